@@ -1,48 +1,177 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"path"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Env                string            `mapstructure:"env"`
-	LogLevel           string            `mapstructure:"log_level"`
-	LogType            string            `mapstructure:"log_type"`
-	ServiceName        string            `mapstructure:"service_name"`
-	Port               string            `mapstructure:"port"`
-	Version            string            `mapstructure:"version"`
-	CorsMaxAgeHours    time.Duration     `mapstructure:"cors_max_age_hours"`
-	RobotsUrlPath      string            `mapstructure:"robots_url_path"`
-	MaxBodySize        int64             `mapstructure:"max_body_size"`
-	PprofEnabled       bool              `mapstructure:"pprof_enabled"`
-	CacheSettings      *CacheConfig      `mapstructure:"cache"`
-	DbSettings         *DatabaseConfig   `mapstructure:"database"`
-	HttpClientSettings *HttpClientConfig `mapstructure:"http_client"`
+	Env                 string             `mapstructure:"env"`
+	LogLevel            string             `mapstructure:"log_level"`
+	LogType             string             `mapstructure:"log_type"`
+	ServiceName         string             `mapstructure:"service_name"`
+	Port                string             `mapstructure:"port"`
+	Version             string             `mapstructure:"version"`
+	CorsMaxAgeHours     time.Duration      `mapstructure:"cors_max_age_hours"`
+	BasePath            string             `mapstructure:"base_path"`
+	RobotsUrlPath       string             `mapstructure:"robots_url_path"`
+	MaxBodySize         int64              `mapstructure:"max_body_size"`
+	StrictQuery         bool               `mapstructure:"strict_query"`
+	PprofEnabled        bool               `mapstructure:"pprof_enabled"`
+	ServerSettings      *ServerConfig      `mapstructure:"server"`
+	LogSettings         *LogConfig         `mapstructure:"log"`
+	CacheSettings       *CacheConfig       `mapstructure:"cache"`
+	DbSettings          *DatabaseConfig    `mapstructure:"database"`
+	PersistenceSettings *PersistenceConfig `mapstructure:"persistence"`
+	HttpClientSettings  *HttpClientConfig  `mapstructure:"http_client"`
+	RobotsSettings      *RobotsConfig      `mapstructure:"robots"`
+	ResponseSettings    *ResponseConfig    `mapstructure:"response"`
+}
+
+type ServerConfig struct {
+	MaxConcurrentRequests  int  `mapstructure:"max_concurrent_requests"`
+	RequireClientUserAgent bool `mapstructure:"require_client_user_agent"`
+}
+
+type LogConfig struct {
+	SampleRate          int  `mapstructure:"sample_rate"`
+	IncludeStackOnError bool `mapstructure:"include_stack_on_error"`
 }
 
 type CacheConfig struct {
-	Servers         string        `mapstructure:"servers"`
-	TtlForRobotsTxt time.Duration `mapstructure:"ttl_for_robots_txt"`
+	Servers              string        `mapstructure:"servers"`
+	TtlForRobotsTxt      time.Duration `mapstructure:"ttl_for_robots_txt"`
+	TtlForIdempotencyKey time.Duration `mapstructure:"ttl_for_idempotency_key"`
+	HealthCheckInterval  time.Duration `mapstructure:"health_check_interval"`
+	MaxInvalidateEntries int           `mapstructure:"max_invalidate_entries"`
+	StartDegraded        bool          `mapstructure:"start_degraded"`
+	ReadTimeout          time.Duration `mapstructure:"read_timeout"`
 }
 
 type DatabaseConfig struct {
 	Host            string        `mapstructure:"host"`
 	Port            string        `mapstructure:"port"`
 	User            string        `mapstructure:"user"`
-	Password        string        `mapstructure:"password"`
+	Password        string        `mapstructure:"password" redact:"true"`
 	Name            string        `mapstructure:"name"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	MaxBulkConns    int           `mapstructure:"max_bulk_conns"`
+}
+
+type PersistenceConfig struct {
+	AsyncWrites         bool          `mapstructure:"async_writes"`
+	WriteQueueSize      int           `mapstructure:"write_queue_size"`
+	WriteWorkers        int           `mapstructure:"write_workers"`
+	WriteBatchSize      int           `mapstructure:"write_batch_size"`
+	WriteFlushInterval  time.Duration `mapstructure:"write_flush_interval"`
+	WriteStatusTtl      time.Duration `mapstructure:"write_status_ttl"`
+	RuleCacheSize       int           `mapstructure:"rule_cache_size"`
+	RuleCacheTtl        time.Duration `mapstructure:"rule_cache_ttl"`
+	SoftDelete          bool          `mapstructure:"soft_delete"`
+	SoftDeleteRetention time.Duration `mapstructure:"soft_delete_retention"`
+	PurgeInterval       time.Duration `mapstructure:"purge_interval"`
+	UsageAccounting     bool          `mapstructure:"usage_accounting"`
+	UsageFlushInterval  time.Duration `mapstructure:"usage_flush_interval"`
+	TryWwwVariant       bool          `mapstructure:"try_www_variant"`
 }
 
 type HttpClientConfig struct {
-	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	RequestTimeout       time.Duration `mapstructure:"request_timeout"`
+	DialTimeout          time.Duration `mapstructure:"dial_timeout"`
+	TlsHandshakeTimeout  time.Duration `mapstructure:"tls_handshake_timeout"`
+	DnsCacheTtl          time.Duration `mapstructure:"dns_cache_ttl"`
+	DeniedNetworks       []string      `mapstructure:"denied_networks"`
+	UserAgent            string        `mapstructure:"user_agent"`
+	MaxRobotsSize        int64         `mapstructure:"max_robots_size"`
+	UseRange             bool          `mapstructure:"use_range"`
+	InsecureSkipVerify   bool          `mapstructure:"insecure_skip_verify"`
+	MaxRedirects         int           `mapstructure:"max_redirects"`
+	PerHostRate          int           `mapstructure:"per_host_rate"`
+	PerHostMaxWait       time.Duration `mapstructure:"per_host_max_wait"`
+	MaxConcurrentFetches int           `mapstructure:"max_concurrent_fetches"`
+	ReadIdleTimeout      time.Duration `mapstructure:"read_idle_timeout"`
+	Accept               string        `mapstructure:"accept"`
+	AcceptLanguage       string        `mapstructure:"accept_language"`
+}
+
+type RobotsConfig struct {
+	DetectHtml           bool          `mapstructure:"detect_html"`
+	RegistrableDomainKey bool          `mapstructure:"registrable_domain_key"`
+	StripWww             bool          `mapstructure:"strip_www"`
+	AllowedUserAgents    []string      `mapstructure:"allowed_user_agents"`
+	DefaultUserAgent     string        `mapstructure:"default_user_agent"`
+	EmptyAgentAsWildcard bool          `mapstructure:"empty_agent_as_wildcard"`
+	StripUserinfo        bool          `mapstructure:"strip_userinfo"`
+	AssumeScheme         string        `mapstructure:"assume_scheme"`
+	MaxSitemaps          int           `mapstructure:"max_sitemaps"`
+	MaxPaths             int           `mapstructure:"max_paths"`
+	BatchConcurrency     int           `mapstructure:"batch_concurrency"`
+	PageCheckEnabled     bool          `mapstructure:"page_check_enabled"`
+	StrictRfc            bool          `mapstructure:"strict_rfc"`
+	DefaultRobotsTxt     string        `mapstructure:"default_robots_txt"`
+	CustomRuleFallback   bool          `mapstructure:"custom_rule_fallback"`
+	AllowOnFetchError    bool          `mapstructure:"allow_on_fetch_error"`
+	StrictCustomRules    bool          `mapstructure:"strict_custom_rules"`
+	ResolutionOrder      string        `mapstructure:"resolution_order"`
+	CustomRuleMaxAge     time.Duration `mapstructure:"custom_rule_max_age"`
+}
+
+// The sources of the robots.txt rules in 'robots.resolution_order'.
+const (
+	ResolutionCustom = "custom"
+	ResolutionCache  = "cache"
+	ResolutionOrigin = "origin"
+)
+
+// DefaultResolutionOrder is used if 'robots.resolution_order' is empty.
+const DefaultResolutionOrder = "custom,cache,origin"
+
+// ParseResolutionOrder splits the comma-separated 'robots.resolution_order' into the sources. It must contain
+// 'custom', 'cache' and 'origin' exactly once, and 'origin' must be last, because the origin always answers and
+// the sources after it would never be reached. An empty order is the default order.
+func ParseResolutionOrder(order string) ([]string, error) {
+	if strings.TrimSpace(order) == "" {
+		order = DefaultResolutionOrder
+	}
+	sources := strings.Split(order, ",")
+	seen := make(map[string]bool, len(sources))
+	for i, source := range sources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source != ResolutionCustom && source != ResolutionCache && source != ResolutionOrigin {
+			return nil, fmt.Errorf("unknown source '%s'", source)
+		}
+		if seen[source] {
+			return nil, fmt.Errorf("source '%s' is repeated", source)
+		}
+		seen[source] = true
+		sources[i] = source
+	}
+	if len(seen) != 3 {
+		return nil, fmt.Errorf("'%s', '%s' and '%s' are required", ResolutionCustom, ResolutionCache,
+			ResolutionOrigin)
+	}
+	if sources[len(sources)-1] != ResolutionOrigin {
+		return nil, fmt.Errorf("'%s' must be last", ResolutionOrigin)
+	}
+	return sources, nil
+}
+
+const (
+	JsonCaseSnake = "snake"
+	JsonCaseCamel = "camel"
+)
+
+type ResponseConfig struct {
+	JsonCase string `mapstructure:"json_case"`
 }
 
 func MustLoad() *Config {
@@ -62,6 +191,152 @@ func MustLoad() *Config {
 		slog.Error("error unmarshalling viper config.", slog.String("err", err.Error()))
 		os.Exit(1)
 	}
+	cfg.applyDefaults()
+	if err := cfg.Validate(); err != nil {
+		slog.Error("invalid config.", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
 
 	return &cfg
 }
+
+// applyDefaults sets the optional sections missing from the config file to their zero settings, so a config
+// without e.g. the 'robots' section starts with the defaults of the section.
+func (c *Config) applyDefaults() {
+	if c.RobotsSettings == nil {
+		c.RobotsSettings = &RobotsConfig{}
+	}
+	if c.PersistenceSettings == nil {
+		c.PersistenceSettings = &PersistenceConfig{}
+	}
+}
+
+const (
+	minTtlForRobotsTxt = time.Second
+	// memcached treats an expiration longer than 30 days as an absolute unix timestamp
+	maxTtlForRobotsTxt = 30 * 24 * time.Hour
+	redactedValue      = "[REDACTED]"
+)
+
+// Validate checks the settings that are unsafe to start with. A zero TTL makes memcached store items forever.
+// The purge interval is required for the soft delete, otherwise the deleted rules are never purged.
+func (c *Config) Validate() error {
+	if c.CacheSettings == nil {
+		return fmt.Errorf("cache settings are missing")
+	}
+	if ttl := c.CacheSettings.TtlForRobotsTxt; ttl < minTtlForRobotsTxt || ttl > maxTtlForRobotsTxt {
+		return fmt.Errorf("cache.ttl_for_robots_txt must be between %s and %s, got %s",
+			minTtlForRobotsTxt, maxTtlForRobotsTxt, ttl)
+	}
+	if d := c.DbSettings; d != nil && d.MaxOpenConns > 0 && d.MaxBulkConns >= d.MaxOpenConns {
+		return fmt.Errorf("database.max_bulk_conns must be less than database.max_open_conns to keep connections "+
+			"for reads, got %d and %d", d.MaxBulkConns, d.MaxOpenConns)
+	}
+	if p := c.PersistenceSettings; p != nil && p.SoftDelete && p.PurgeInterval <= 0 {
+		return fmt.Errorf("persistence.purge_interval must be positive when soft delete is enabled")
+	}
+	if p := c.PersistenceSettings; p != nil && p.UsageAccounting && p.UsageFlushInterval <= 0 {
+		return fmt.Errorf("persistence.usage_flush_interval must be positive when usage accounting is enabled")
+	}
+	if r := c.RobotsSettings; r != nil && r.AssumeScheme != "" && r.AssumeScheme != "http" && r.AssumeScheme != "https" {
+		return fmt.Errorf("robots.assume_scheme must be empty, 'http' or 'https', got '%s'", r.AssumeScheme)
+	}
+	if r := c.RobotsSettings; r != nil {
+		if _, err := ParseResolutionOrder(r.ResolutionOrder); err != nil {
+			return fmt.Errorf("robots.resolution_order is invalid, got '%s'. %w", r.ResolutionOrder, err)
+		}
+	}
+	if r := c.ResponseSettings; r != nil && r.JsonCase != "" && r.JsonCase != JsonCaseSnake && r.JsonCase != JsonCaseCamel {
+		return fmt.Errorf("response.json_case must be '%s' or '%s', got '%s'", JsonCaseSnake, JsonCaseCamel, r.JsonCase)
+	}
+	return nil
+}
+
+// defaultMaxRobotsSize is the robots.txt size limit in bytes if neither 'http_client.max_robots_size'
+// nor 'max_body_size' is set.
+const defaultMaxRobotsSize = 500 * 1024
+
+// MaxRobotsSize returns the max size of robots.txt in bytes: 'http_client.max_robots_size' (KB), or the global
+// 'max_body_size' (MB) if it is not set. A zero limit would truncate every robots.txt to nothing.
+func (c *Config) MaxRobotsSize() int64 {
+	switch {
+	case c.HttpClientSettings != nil && c.HttpClientSettings.MaxRobotsSize > 0:
+		return c.HttpClientSettings.MaxRobotsSize * 1024
+	case c.MaxBodySize > 0:
+		return c.MaxBodySize * 1024 * 1024
+	default:
+		return defaultMaxRobotsSize
+	}
+}
+
+// Effective returns the loaded settings keyed by the config file names. Durations are formatted as strings
+// and the fields tagged with 'redact' are masked.
+func (c *Config) Effective() map[string]any {
+	return effective(reflect.ValueOf(c).Elem())
+}
+
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// Sources returns where every setting of the loaded config comes from, keyed by the dotted config file names
+// (e.g. 'database.port'): 'env' if the environment variable overrides it, 'file' if it is set in the config file,
+// otherwise 'default' (the zero value). The sources of the fields tagged with 'redact' are masked.
+func Sources() map[string]string {
+	sources := make(map[string]string)
+	collectSources(reflect.TypeOf(Config{}), "", sources)
+	return sources
+}
+
+func collectSources(t reflect.Type, prefix string, sources map[string]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		switch {
+		case field.Tag.Get("redact") == "true":
+			sources[key] = redactedValue
+		case field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct:
+			collectSources(field.Type.Elem(), key+".", sources)
+		default:
+			sources[key] = source(key)
+		}
+	}
+}
+
+// source reports where the key comes from. viper.AutomaticEnv reads the environment variable named as the
+// upper-case key, e.g. 'DATABASE.PORT', and it takes precedence over the config file.
+func source(key string) string {
+	if _, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+func effective(v reflect.Value) map[string]any {
+	settings := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("redact") == "true":
+			settings[key] = redactedValue
+		case value.Type() == reflect.TypeOf(time.Duration(0)):
+			settings[key] = time.Duration(value.Int()).String()
+		case value.Kind() == reflect.Pointer && value.Type().Elem().Kind() == reflect.Struct:
+			if value.IsNil() {
+				settings[key] = nil
+			} else {
+				settings[key] = effective(value.Elem())
+			}
+		default:
+			settings[key] = value.Interface()
+		}
+	}
+	return settings
+}
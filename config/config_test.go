@@ -0,0 +1,219 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	testSet := []struct {
+		name          string
+		ttl           time.Duration
+		expectedError bool
+	}{
+		{name: "valid ttl", ttl: 24 * time.Hour},
+		{name: "zero ttl", ttl: 0, expectedError: true},
+		{name: "negative ttl", ttl: -time.Hour, expectedError: true},
+		{name: "ttl below one second", ttl: 500 * time.Millisecond, expectedError: true},
+		{name: "ttl longer than 30 days", ttl: 31 * 24 * time.Hour, expectedError: true},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &Config{CacheSettings: &CacheConfig{TtlForRobotsTxt: test.ttl}}
+			err := cfg.Validate()
+			if test.expectedError {
+				assert.ErrorContains(tt, err, "cache.ttl_for_robots_txt")
+			} else {
+				assert.NoError(tt, err)
+			}
+		})
+	}
+}
+
+func Test_ApplyDefaults(t *testing.T) {
+	robots := &RobotsConfig{DetectHtml: true}
+	persistence := &PersistenceConfig{SoftDelete: true}
+	testSet := []struct {
+		name                string
+		cfg                 *Config
+		expectedRobots      *RobotsConfig
+		expectedPersistence *PersistenceConfig
+	}{
+		{name: "missing sections get the zero settings", cfg: &Config{}, expectedRobots: &RobotsConfig{},
+			expectedPersistence: &PersistenceConfig{}},
+		{name: "loaded sections are kept", cfg: &Config{RobotsSettings: robots, PersistenceSettings: persistence},
+			expectedRobots: robots, expectedPersistence: persistence},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			test.cfg.applyDefaults()
+
+			assert.Equal(tt, test.expectedRobots, test.cfg.RobotsSettings)
+			assert.Equal(tt, test.expectedPersistence, test.cfg.PersistenceSettings)
+		})
+	}
+}
+
+func Test_Validate_PurgeInterval(t *testing.T) {
+	cfg := &Config{
+		CacheSettings:       &CacheConfig{TtlForRobotsTxt: time.Hour},
+		PersistenceSettings: &PersistenceConfig{SoftDelete: true},
+	}
+	assert.ErrorContains(t, cfg.Validate(), "persistence.purge_interval")
+
+	cfg.PersistenceSettings.PurgeInterval = time.Hour
+	assert.NoError(t, cfg.Validate())
+}
+
+func Test_Validate_UsageFlushInterval(t *testing.T) {
+	cfg := &Config{
+		CacheSettings:       &CacheConfig{TtlForRobotsTxt: time.Hour},
+		PersistenceSettings: &PersistenceConfig{UsageAccounting: true},
+	}
+	assert.ErrorContains(t, cfg.Validate(), "persistence.usage_flush_interval")
+
+	cfg.PersistenceSettings.UsageFlushInterval = time.Minute
+	assert.NoError(t, cfg.Validate())
+}
+
+func Test_Validate_MaxBulkConns(t *testing.T) {
+	cfg := &Config{
+		CacheSettings: &CacheConfig{TtlForRobotsTxt: time.Hour},
+		DbSettings:    &DatabaseConfig{MaxOpenConns: 2, MaxBulkConns: 2},
+	}
+	assert.ErrorContains(t, cfg.Validate(), "database.max_bulk_conns")
+
+	cfg.DbSettings.MaxBulkConns = 1
+	assert.NoError(t, cfg.Validate())
+}
+
+func Test_Validate_JsonCase(t *testing.T) {
+	cfg := &Config{
+		CacheSettings:    &CacheConfig{TtlForRobotsTxt: time.Hour},
+		ResponseSettings: &ResponseConfig{JsonCase: "kebab"},
+	}
+	assert.ErrorContains(t, cfg.Validate(), "response.json_case")
+
+	cfg.ResponseSettings.JsonCase = JsonCaseCamel
+	assert.NoError(t, cfg.Validate())
+}
+
+func Test_Validate_AssumeScheme(t *testing.T) {
+	cfg := &Config{
+		CacheSettings:  &CacheConfig{TtlForRobotsTxt: time.Hour},
+		RobotsSettings: &RobotsConfig{AssumeScheme: "ftp"},
+	}
+	assert.ErrorContains(t, cfg.Validate(), "robots.assume_scheme")
+
+	cfg.RobotsSettings.AssumeScheme = "https"
+	assert.NoError(t, cfg.Validate())
+}
+
+func Test_Validate_ResolutionOrder(t *testing.T) {
+	testSet := []struct {
+		name          string
+		order         string
+		expectedError string
+	}{
+		{name: "default order", order: ""},
+		{name: "custom rule first", order: "custom,cache,origin"},
+		{name: "cache first with spaces", order: "cache, custom, origin"},
+		{name: "missing source", order: "cache,custom", expectedError: "are required"},
+		{name: "repeated source", order: "cache,custom,cache", expectedError: "is repeated"},
+		{name: "repeated origin", order: "cache,custom,origin,origin", expectedError: "is repeated"},
+		{name: "unknown source", order: "cache,database,origin", expectedError: "unknown source"},
+		{name: "origin before cache", order: "custom,origin,cache", expectedError: "must be last"},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &Config{
+				CacheSettings:  &CacheConfig{TtlForRobotsTxt: time.Hour},
+				RobotsSettings: &RobotsConfig{ResolutionOrder: test.order},
+			}
+			err := cfg.Validate()
+			if test.expectedError == "" {
+				assert.NoError(tt, err)
+			} else {
+				assert.ErrorContains(tt, err, "robots.resolution_order")
+				assert.ErrorContains(tt, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func Test_ParseResolutionOrder(t *testing.T) {
+	sources, err := ParseResolutionOrder("Cache,custom,origin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ResolutionCache, ResolutionCustom, ResolutionOrigin}, sources)
+
+	sources, err = ParseResolutionOrder("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ResolutionCustom, ResolutionCache, ResolutionOrigin}, sources)
+}
+
+func Test_MaxRobotsSize(t *testing.T) {
+	testSet := []struct {
+		name     string
+		cfg      *Config
+		expected int64
+	}{
+		{
+			name:     "robots size limit",
+			cfg:      &Config{MaxBodySize: 2, HttpClientSettings: &HttpClientConfig{MaxRobotsSize: 500}},
+			expected: 500 * 1024,
+		},
+		{
+			name:     "body size limit without the robots size limit",
+			cfg:      &Config{MaxBodySize: 2, HttpClientSettings: &HttpClientConfig{}},
+			expected: 2 * 1024 * 1024,
+		},
+		{
+			name:     "default without both limits",
+			cfg:      &Config{},
+			expected: 500 * 1024,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, test.cfg.MaxRobotsSize())
+		})
+	}
+}
+
+func Test_Effective(t *testing.T) {
+	cfg := &Config{
+		Port:          "8081",
+		CacheSettings: &CacheConfig{Servers: "cache:11211", TtlForRobotsTxt: 24 * time.Hour},
+		DbSettings:    &DatabaseConfig{User: "admin", Password: "secret"},
+	}
+
+	settings := cfg.Effective()
+
+	assert.Equal(t, "8081", settings["port"])
+	assert.Equal(t, "24h0m0s", settings["cache"].(map[string]any)["ttl_for_robots_txt"])
+	assert.Equal(t, "cache:11211", settings["cache"].(map[string]any)["servers"])
+	assert.Equal(t, "admin", settings["database"].(map[string]any)["user"])
+	assert.Equal(t, "[REDACTED]", settings["database"].(map[string]any)["password"])
+	assert.Nil(t, settings["robots"])
+}
+
+func Test_Sources(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.SetConfigType("yaml")
+	viper.AutomaticEnv()
+	assert.NoError(t, viper.ReadConfig(strings.NewReader("port: \"8080\"\ndatabase:\n  port: \"3306\"\n"+
+		"  password: \"secret\"\n")))
+	t.Setenv("DATABASE.PORT", "3307")
+
+	sources := Sources()
+
+	assert.Equal(t, SourceFile, sources["port"])
+	assert.Equal(t, SourceEnv, sources["database.port"])
+	assert.Equal(t, SourceDefault, sources["robots.assume_scheme"])
+	assert.Equal(t, "[REDACTED]", sources["database.password"])
+}
@@ -0,0 +1,18 @@
+package database
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migration/*.sql
+var migrations embed.FS
+
+// Migrations returns the SQL migration files. The files are applied in the lexical order of their names.
+func Migrations() fs.FS {
+	sub, err := fs.Sub(migrations, "migration")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,4 @@
+USE url_scraper;
+
+ALTER TABLE custom_rule
+    ADD COLUMN note VARCHAR(255) NULL AFTER robots_txt; -- why the rule exists, e.g. 'legal hold for client X'
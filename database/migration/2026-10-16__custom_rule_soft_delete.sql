@@ -0,0 +1,3 @@
+ALTER TABLE custom_rule
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL, -- set by the soft delete, the rule is purged later
+    ADD INDEX deleted_at_index (deleted_at);
@@ -0,0 +1,2 @@
+ALTER TABLE custom_rule
+    ADD COLUMN source_url VARCHAR(2048) NULL AFTER note; -- robots.txt url the rule is imported from, NULL if uploaded
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS api_key_usage
+(
+    api_key VARCHAR(64) NOT NULL, -- the sha256 hash, same as in assessor_api_key
+    day     DATE        NOT NULL, -- UTC day of the calls
+    calls   BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key, day)
+) ENGINE = InnoDB
+  CHARSET = utf8;
@@ -15,6 +15,221 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch robots.txt from origin, bypassing custom rules and cache, and report whether it is reachable\nand well-formed: the status code, size, user-agent groups, sitemaps, validation warnings\nand the followed redirects",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Audit robots.txt of a site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the site",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit report",
+                        "schema": {
+                            "$ref": "#/definitions/model.RobotsAudit"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or url with credentials",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/cache/invalidate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete cached robots.txt files for the given urls and domains. The result is reported for every entry\nwith the batch summary. The error index is the position of the entry in the urls followed by the domains.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Invalidate cached robots.txt files",
+                "parameters": [
+                    {
+                        "description": "Urls and domains to invalidate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CacheInvalidationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Batch summary and invalidation result of every entry",
+                        "schema": {
+                            "$ref": "#/definitions/model.CacheInvalidationSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid body, no entries or too many entries",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/cache/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch the robots.txt file of the url from origin bypassing the cache and save it to cache,\ne.g. to pre-warm the cache or to apply a changed robots.txt before the cached file expires",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Refresh the cached robots.txt file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the site",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Origin status code and size of the fetched robots.txt",
+                        "schema": {
+                            "$ref": "#/definitions/model.CacheRefresh"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or url with credentials",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/cache/servers": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the configured cache servers and their reachability from the periodic health check",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Get cache servers",
+                "responses": {
+                    "200": {
+                        "description": "Cache servers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CacheServer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/config/effective": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the loaded configuration, including the environment overrides. Secrets are redacted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "Get effective configuration",
+                "responses": {
+                    "200": {
+                        "description": "Effective configuration",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/config/sources": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Report whether every setting comes from an environment variable ('env'), the config file ('file')\nor is not set ('default'), e.g. to find out why a setting doesn't take effect. Secrets are redacted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Config"
+                ],
+                "summary": "Get the source of every configuration setting",
+                "responses": {
+                    "200": {
+                        "description": "Source of every setting keyed by the dotted name, e.g. 'database.port'",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/custom-rule": {
             "get": {
                 "security": [
@@ -42,17 +257,40 @@ const docTemplate = `{
                         "description": "Custom rule URL",
                         "name": "url",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "404",
+                            "204"
+                        ],
+                        "type": "string",
+                        "description": "Status code if the rule doesn't exist: 404 (default) or 204",
+                        "name": "on_missing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add 'age_seconds' and 'last_updated_seconds' of the rule",
+                        "name": "include_freshness",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Custom rule object",
+                        "description": "Custom rule object. The field names are camelCase if 'response.json_case' is camel",
                         "schema": {
                             "$ref": "#/definitions/model.Rule"
                         }
                     },
+                    "204": {
+                        "description": "Rule not found and 'on_missing' is 204"
+                    },
                     "400": {
-                        "description": "Bad request. Either 'id' or 'url' must be provided",
+                        "description": "Bad request. Either 'id' or 'url' must be provided, not both",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Rule not found",
                         "schema": {}
                     },
                     "500": {
@@ -88,10 +326,15 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "New URL for the custom rule",
+                        "description": "New URL for the custom rule. The domain is not changed if it is omitted",
                         "name": "url",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "New note for the custom rule. The note is not changed if it is omitted and removed if it is empty",
+                        "name": "note",
+                        "in": "query"
                     },
                     {
                         "description": "Updated custom rule file content",
@@ -105,13 +348,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Updated custom rule",
+                        "description": "Updated custom rule. The unchanged rule with 'not_modified: true' if nothing changed",
                         "schema": {
                             "$ref": "#/definitions/model.Rule"
                         }
                     },
                     "400": {
-                        "description": "Bad request, missing 'id' or invalid data to update",
+                        "description": "Bad request, missing 'id', invalid data to update or too long note",
                         "schema": {}
                     },
                     "404": {
@@ -121,6 +364,10 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal server error",
                         "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {}
                     }
                 }
             },
@@ -130,7 +377,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new custom rule by providing a URL and the corresponding rule file",
+                "description": "Create a new custom rule by providing a URL and the corresponding rule file.\nIf asynchronous writes are enabled, the rule is queued and the tracking id is returned.",
                 "consumes": [
                     "text/plain"
                 ],
@@ -149,6 +396,18 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Human-readable note on why the rule exists. Max 255 characters",
+                        "name": "note",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Repeated requests with the same key return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Custom rule file content",
                         "name": "file",
@@ -161,18 +420,28 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Custom rule created successfully",
+                        "description": "Custom rule created successfully. The id and the normalized domain are returned",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "202": {
+                        "description": "Custom rule queued for creation. The tracking id and the normalized domain are returned",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Bad request, missing 'url' or empty file",
+                        "description": "Bad request, missing 'url', empty file or too long note",
                         "schema": {}
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {}
                     }
                 }
             },
@@ -211,65 +480,1350 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal server error",
                         "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {}
                     }
                 }
             }
         },
-        "/scrape-allowed": {
+        "/custom-rule/diff": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Check if the given user agent is allowed to scrape the specified URL based on the robots.txt rules",
+                "description": "Fetch the live robots.txt file of the url and compare it line by line with the custom rule of the\ndomain, e.g. to find the stale custom rules. The cache is not used and not changed.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Scraping"
+                    "Custom Rule"
                 ],
-                "summary": "Check if scraping is allowed for a specific user agent and URL",
+                "summary": "Compare a custom rule with the origin robots.txt",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "URL to check",
+                        "description": "URL of the site",
                         "name": "url",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User agent to check",
-                        "name": "user_agent",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "true or false depending on whether scraping is allowed",
+                        "description": "Line-level diff and whether the content is identical",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.RuleDiff"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad request, missing 'url' or 'user_agent'",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "Bad request, missing 'url' or url with credentials",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Rule not found",
+                        "schema": {}
                     },
                     "500": {
                         "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "502": {
+                        "description": "Origin responded without robots.txt, e.g. with 404 or an empty file",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable or origin is rate limiting robots.txt requests",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/custom-rule/domains": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the ids and domains of the custom rules ordered by the domain, without the rule content",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Custom Rule"
+                ],
+                "summary": "List the domains with custom rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Max number of domains, 100 by default and 1000 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of domains to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ids and domains of the custom rules",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RuleDomain"
+                            }
                         }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid 'limit' or 'offset'",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {}
                     }
                 }
             }
-        }
+        },
+        "/custom-rule/from-origin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetch the live robots.txt file of the url and save it as a custom rule. The fetched robots.txt url\nis saved in 'source_url' of the rule. The cache is not used and not changed.\nIf asynchronous writes are enabled, the rule is queued and the tracking id is returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Custom Rule"
+                ],
+                "summary": "Create a custom rule from the origin robots.txt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the site",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Human-readable note on why the rule exists. Max 255 characters",
+                        "name": "note",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Custom rule created successfully. The id and the normalized domain are returned",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Custom rule queued for creation. The tracking id and the normalized domain are returned",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url', url with credentials or too long note",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "502": {
+                        "description": "Origin responded without robots.txt, e.g. with 404 or an empty file",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable or origin is rate limiting robots.txt requests",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/custom-rule/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the state of the queued custom rule write by the tracking id returned from the create request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Custom Rule"
+                ],
+                "summary": "Get the status of an asynchronous custom rule creation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tracking ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Write status",
+                        "schema": {
+                            "$ref": "#/definitions/model.WriteStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'id' or asynchronous writes are disabled",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Write not found",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/custom-rule/validate-all": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Run the robots.txt validator on every stored custom rule and report the rules with warnings,\ne.g. to schedule a cleanup. The rules are loaded in batches, so the memory is bounded for large tables",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Custom Rule"
+                ],
+                "summary": "Validate all stored custom rules",
+                "responses": {
+                    "200": {
+                        "description": "Number of checked rules and the rules with warnings",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleValidationReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/debug/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Report the number of goroutines, heap and GC stats and the database connection pool stats,\ne.g. to spot goroutine or connection leaks. Reading the memory stats briefly stops the world",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debug"
+                ],
+                "summary": "Get the goroutine, memory and database connection stats",
+                "responses": {
+                    "200": {
+                        "description": "Runtime and database connection stats",
+                        "schema": {
+                            "$ref": "#/definitions/model.DebugStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/effective-robots": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolve the rules the same way as the scrape check (custom rule, cache or origin) and return\nthe robots.txt content. The content is limited by 'http_client.max_robots_size'",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Get the robots.txt content used for the scrape check of the url",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "robots.txt content",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "X-Robots-Hash": {
+                                "type": "string",
+                                "description": "Hex sha256 hash of the resolved robots.txt, before the truncation"
+                            },
+                            "X-Robots-Length": {
+                                "type": "integer",
+                                "description": "Length of the resolved robots.txt in bytes, before the truncation"
+                            },
+                            "X-Robots-Source": {
+                                "type": "string",
+                                "description": "Source of the rules: 'custom', 'cache' or 'origin'"
+                            },
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            },
+                            "X-Robots-Truncated": {
+                                "type": "string",
+                                "description": "'true' if the content is truncated"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or url with credentials",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/matched-group": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolve the rules the same way as the scrape check and return the user-agent values of the groups\nselected for the user agent, and whether the '*' group is used as a fallback",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Get the robots.txt groups that apply to the user agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent to match. Required if 'robots.default_user_agent' is not configured",
+                        "name": "user_agent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User agents of the matched groups",
+                        "schema": {
+                            "$ref": "#/definitions/util.MatchedGroup"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/page-allowed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Combine the robots.txt decision with the X-Robots-Tag header of the page, fetched with a HEAD request.\nThe page is not requested if robots.txt disallows crawling it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Check if the page is allowed to be crawled and indexed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the page",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent to check. Required if 'robots.default_user_agent' is not configured",
+                        "name": "user_agent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Crawl and index permissions",
+                        "schema": {
+                            "$ref": "#/definitions/model.PagePermissions"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "502": {
+                        "description": "Failed to fetch the page",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/robots-meta": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolve the rules the same way as the scrape check (custom rule, cache or origin) and report\ntheir source, size, number of user-agent groups and sitemaps. The number of sitemaps is limited\nby 'robots.max_sitemaps'. The Visit-time (preferred crawl window) is of the group that applies\nto 'user_agent', or of the '*' group if 'user_agent' is not sent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Get the summary of the robots.txt rules for the url",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent whose group the Visit-time is taken from. The '*' group by default",
+                        "name": "user_agent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Summary of the robots.txt rules",
+                        "schema": {
+                            "$ref": "#/definitions/model.RobotsMeta"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or url with credentials",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/scrape-allowed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check if the given user agent is allowed to scrape the specified URL based on the robots.txt rules.\nThe errors are plain text, or JSON if the Accept header prefers application/json",
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Check if scraping is allowed for a specific user agent and URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent to check. Required if 'robots.default_user_agent' is not configured",
+                        "name": "user_agent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the rule that decided the result as JSON",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64-encoded robots.txt content to check instead of the custom rule, cache and origin. Requires X-API-Key",
+                        "name": "X-Robots-Override",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "true or false depending on whether scraping is allowed. JSON explanation with confidence if 'explain' is true",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "max-age of the decision: the remaining TTL of the cached robots.txt or 'robots.custom_rule_max_age' for the custom rule"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the robots.txt content, user agent and url the decision is based on"
+                            },
+                            "X-Robots-Hash": {
+                                "type": "string",
+                                "description": "Hex sha256 hash of the robots.txt the decision is based on"
+                            },
+                            "X-Robots-Length": {
+                                "type": "integer",
+                                "description": "Length of the robots.txt the decision is based on in bytes"
+                            },
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache', 'custom' or 'override'"
+                            },
+                            "X-Robots-Warning": {
+                                "type": "string",
+                                "description": "'fetch-failed' if robots.txt couldn't be fetched and the url is allowed by default"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "The decision is unchanged since the response with the ETag from If-None-Match",
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "max-age of the decision: the remaining TTL of the cached robots.txt or 'robots.custom_rule_max_age' for the custom rule"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the robots.txt content, user agent and url the decision is based on"
+                            },
+                            "X-Robots-Hash": {
+                                "type": "string",
+                                "description": "Hex sha256 hash of the robots.txt the decision is based on"
+                            },
+                            "X-Robots-Length": {
+                                "type": "integer",
+                                "description": "Length of the robots.txt the decision is based on in bytes"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or 'user_agent', url with credentials, the user agent is not in the allowlist or invalid X-Robots-Override",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "The custom rule is unparseable and 'robots.strict_custom_rules' is enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Evaluate the url and user agent against the robots.txt content from the body. Custom rules, cache\nand origin are not used and nothing is saved. An empty body allows everything.\nThe errors are plain text, or JSON if the Accept header prefers application/json",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Check if scraping is allowed by the robots.txt content from the request body",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent to check. Required if 'robots.default_user_agent' is not configured",
+                        "name": "user_agent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the rule that decided the result as JSON",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "robots.txt content",
+                        "name": "file",
+                        "in": "body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "true or false depending on whether scraping is allowed. JSON explanation with confidence if 'explain' is true",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/scrape-allowed/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check every url for the user agent. The urls are grouped by the origin internally, so the robots.txt\nrules of every origin are resolved once, but the results are returned in the order of the request urls,\nduplicates included, so they can be matched to the urls by index. The number of urls is limited by\n'robots.max_paths'. Up to 'robots.batch_concurrency' origins are resolved concurrently.\nA failure of one url (invalid url or robots.txt load error) doesn't fail the batch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Check if scraping is allowed for many urls of any domains",
+                "parameters": [
+                    {
+                        "description": "User agent and urls to check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BatchCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Batch summary and decision of every url in the request order",
+                        "schema": {
+                            "$ref": "#/definitions/model.BatchCheckResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid body, no or too many urls, missing 'user_agent' or the user agent is not in the allowlist",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/scrape-allowed/paths": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolve the robots.txt rules of the domain once (custom rule, cache or origin) and check every path\nfor the user agent. The number of paths is limited by 'robots.max_paths'",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Check if scraping is allowed for many paths of one domain",
+                "parameters": [
+                    {
+                        "description": "Domain, user agent and paths to check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PathsCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Decision of every path, e.g. {\\\"/a\\\":true,\\\"/b\\\":false}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid domain or path, no or too many paths, missing 'user_agent' or the user agent is not in the allowlist",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/sitemap-allowed": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check the robots.txt decision for the sitemap url and whether the sitemap is declared in robots.txt.\nThe rules are resolved the same way as the scrape check (custom rule, cache or origin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Check if the sitemap is allowed to be fetched",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL of the sitemap",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent to check. Required if 'robots.default_user_agent' is not configured",
+                        "name": "user_agent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fetch permission and whether the sitemap is declared",
+                        "schema": {
+                            "$ref": "#/definitions/model.SitemapPermissions"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/sitemaps": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolve the rules the same way as the scrape check (custom rule, cache or origin) and return\nthe sitemaps. The number of sitemaps is limited by 'robots.max_sitemaps'",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Scraping"
+                ],
+                "summary": "Get the sitemaps of the robots.txt rules for the url",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sitemaps and whether the list is truncated",
+                        "schema": {
+                            "$ref": "#/definitions/model.Sitemaps"
+                        },
+                        "headers": {
+                            "X-Robots-Status": {
+                                "type": "string",
+                                "description": "Origin status code of robots.txt, 'cache' or 'custom'"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing 'url' or url with credentials",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the number of authenticated calls of every API key between the UTC days, both inclusive.\nThe calls are saved periodically, so the last calls may be missing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usage"
+                ],
+                "summary": "Get the number of calls of every API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, e.g. 2024-10-01",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, e.g. 2024-10-31",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Calls of every API key",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ApiKeyUsage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, missing or invalid 'from' or 'to'",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "Database is unavailable",
+                        "schema": {}
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "model.ApiKeyUsage": {
+            "description": "Represents the number of calls made with an API key in the requested period",
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string"
+                },
+                "calls": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BatchCheckRequest": {
+            "description": "Represents the urls of any domains to check against their robots.txt rules",
+            "type": "object",
+            "properties": {
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BatchCheckResult": {
+            "description": "Represents the batch summary and the decision of every url in the order of the request",
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BatchError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UrlDecision"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.BatchError": {
+            "description": "Represents the failure of one item of a batch operation. Index is the position of the item in the request",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CacheInvalidation": {
+            "description": "Represents the result of the cache invalidation for one url or domain",
+            "type": "object",
+            "properties": {
+                "entry": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "invalidated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.CacheInvalidationRequest": {
+            "description": "Represents the urls and domains, which cached robots.txt files should be invalidated",
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.CacheInvalidationSummary": {
+            "description": "Represents the batch summary of the cache invalidation and the result of every url and domain",
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BatchError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CacheInvalidation"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CacheRefresh": {
+            "description": "Represents the robots.txt file fetched from origin and saved to cache by the refresh",
+            "type": "object",
+            "properties": {
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CacheServer": {
+            "description": "Represents a configured cache server and its reachability from the last health check",
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "last_checked": {
+                    "type": "string"
+                },
+                "reachable": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.DebugStats": {
+            "description": "Represents the runtime and database connection pool stats of the service",
+            "type": "object",
+            "properties": {
+                "db_idle": {
+                    "type": "integer"
+                },
+                "db_in_use": {
+                    "type": "integer"
+                },
+                "db_open_connections": {
+                    "type": "integer"
+                },
+                "gc_cycles": {
+                    "type": "integer"
+                },
+                "gc_pause_total_ms": {
+                    "type": "integer"
+                },
+                "goroutines": {
+                    "type": "integer"
+                },
+                "heap_alloc_bytes": {
+                    "type": "integer"
+                },
+                "heap_objects": {
+                    "type": "integer"
+                },
+                "last_gc_unix": {
+                    "type": "integer"
+                },
+                "sys_bytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.DiffLine": {
+            "description": "Represents a line of the diff. Op is 'equal', 'removed' (only in the custom rule) or 'added' (only in the origin robots.txt)",
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PagePermissions": {
+            "description": "Represents whether the page is allowed to be crawled by robots.txt and indexed by X-Robots-Tag header",
+            "type": "object",
+            "properties": {
+                "crawl_allowed": {
+                    "type": "boolean"
+                },
+                "index_allowed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.PathsCheckRequest": {
+            "description": "Represents the paths of one domain to check against its robots.txt rules",
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "paths": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RobotsAudit": {
+            "description": "Represents the health of the robots.txt file fetched from origin",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "exceeds_size_limit": {
+                    "type": "boolean"
+                },
+                "max_size": {
+                    "type": "integer"
+                },
+                "reachable": {
+                    "type": "boolean"
+                },
+                "redirects": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sitemaps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_agent_groups": {
+                    "type": "integer"
+                },
+                "user_agents": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.RobotsTxtWarning"
+                    }
+                }
+            }
+        },
+        "model.RobotsMeta": {
+            "description": "Represents the summary of the robots.txt rules used for the url",
+            "type": "object",
+            "properties": {
+                "sitemaps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sitemaps_truncated": {
+                    "type": "boolean"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_agent_groups": {
+                    "type": "integer"
+                },
+                "user_agents": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "visit_time": {
+                    "$ref": "#/definitions/util.VisitTime"
+                }
+            }
+        },
         "model.Rule": {
             "description": "Represents a custom rule for a domain",
             "type": "object",
@@ -283,13 +1837,202 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "note": {
+                    "type": "string"
+                },
                 "robots_txt": {
                     "type": "string"
                 },
+                "source_url": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RuleDiff": {
+            "description": "Represents the line-level diff of the custom rule (removed lines) and the live origin robots.txt (added lines)",
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DiffLine"
+                    }
+                },
+                "identical": {
+                    "type": "boolean"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "source_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RuleDomain": {
+            "description": "Represents the domain of a custom rule without the rule content",
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RuleValidation": {
+            "description": "Represents the validation warnings of a stored custom rule",
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.RobotsTxtWarning"
+                    }
+                }
+            }
+        },
+        "model.RuleValidationReport": {
+            "description": "Represents the validation of all stored custom rules. Only the rules with warnings are listed",
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RuleValidation"
+                    }
+                }
+            }
+        },
+        "model.SitemapPermissions": {
+            "description": "Represents whether the sitemap is allowed to be fetched by robots.txt and is declared in it",
+            "type": "object",
+            "properties": {
+                "declared_in_robots": {
+                    "type": "boolean"
+                },
+                "fetch_allowed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.Sitemaps": {
+            "description": "Represents the sitemaps listed in the robots.txt rules used for the url",
+            "type": "object",
+            "properties": {
+                "sitemaps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "type": "string"
+                },
+                "truncated": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UrlDecision": {
+            "description": "Represents the scrape decision for one url of the batch. Allowed is false if the url has an error",
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WriteStatus": {
+            "description": "Represents the state of an asynchronous custom rule write",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tracking_id": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
+        },
+        "util.MatchedGroup": {
+            "type": "object",
+            "properties": {
+                "user_agents": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "wildcard": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "util.RobotsTxtWarning": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "util.VisitTime": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
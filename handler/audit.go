@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRobotsTxt godoc
+// @Summary Audit robots.txt of a site
+// @Description Fetch robots.txt from origin, bypassing custom rules and cache, and report whether it is reachable
+// @Description and well-formed: the status code, size, user-agent groups, sitemaps, validation warnings
+// @Description and the followed redirects
+// @Tags Audit
+// @Produce json
+// @Param url query string true "URL of the site"
+// @Success 200 {object} model.RobotsAudit "Audit report"
+// @Failure 400 {object} error "Bad request, missing 'url' or url with credentials"
+// @Security ApiKeyAuth
+// @Router /audit [post]
+func (h *RobotsHandler) AuditRobotsTxt(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+
+	audit := &model.RobotsAudit{
+		Url:        url,
+		MaxSize:    h.maxRobotsSize(),
+		UserAgents: []string{},
+		Sitemaps:   []string{},
+		Warnings:   []util.RobotsTxtWarning{},
+		Redirects:  []string{},
+	}
+	resp, err := h.fetchRobotsTxt(url)
+	if err != nil {
+		audit.Error = err.Error()
+		c.JSON(http.StatusOK, audit)
+		return
+	}
+	audit.Reachable = true
+	audit.StatusCode = resp.statusCode
+	audit.Size = resp.size
+	audit.ExceedsSizeLimit = resp.truncated || resp.size > audit.MaxSize
+	if resp.redirects != nil {
+		audit.Redirects = resp.redirects
+	}
+	if !isSuccess(resp.statusCode) {
+		c.JSON(http.StatusOK, audit)
+		return
+	}
+
+	report := util.ValidateRobotsTxt(string(resp.body))
+	audit.UserAgentGroups = report.UserAgentGroups
+	audit.UserAgents = util.DeclaredUserAgents(string(resp.body))
+	audit.Sitemaps = report.Sitemaps
+	audit.Warnings = report.Warnings
+	if util.IsHtml(resp.contentType, resp.body) {
+		audit.Warnings = append([]util.RobotsTxtWarning{{Message: "robots.txt is served as html",
+			Severity: util.SeverityError}}, audit.Warnings...)
+	}
+	if audit.ExceedsSizeLimit {
+		audit.Warnings = append([]util.RobotsTxtWarning{{
+			Message:  "robots.txt exceeds the size limit. The content after the limit is ignored",
+			Severity: util.SeverityWarning,
+		}}, audit.Warnings...)
+	}
+
+	c.JSON(http.StatusOK, audit)
+}
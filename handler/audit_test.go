@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditRobotsTxt_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name                 string
+		url                  string
+		mockHttpResponseCode int
+		mockHttpResponseBody string
+		expectedAudit        *model.RobotsAudit
+		expectedStatusCode   int
+	}{
+		{
+			name:                 "healthy robots.txt",
+			url:                  "https://example.com/test",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: googlebot\nDisallow: /private\n\nUser-agent: *\nAllow: /\n" +
+				"Sitemap: https://example.com/sitemap.xml\n",
+			expectedAudit: &model.RobotsAudit{
+				Url:             "https://example.com/test",
+				Reachable:       true,
+				StatusCode:      http.StatusOK,
+				Size:            106,
+				MaxSize:         1024,
+				UserAgentGroups: 2,
+				UserAgents:      []string{"googlebot", "*"},
+				Sitemaps:        []string{"https://example.com/sitemap.xml"},
+				Warnings:        []util.RobotsTxtWarning{},
+				Redirects:       []string{},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "malformed oversized robots.txt",
+			url:                  "https://example.com/test",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "Disallow: /before-agent\nUser-agent: *\nDisallow: private\n# " +
+				strings.Repeat("x", 2048) + "\n",
+			expectedAudit: &model.RobotsAudit{
+				Url:              "https://example.com/test",
+				Reachable:        true,
+				StatusCode:       http.StatusOK,
+				Size:             2107,
+				MaxSize:          1024,
+				ExceedsSizeLimit: true,
+				UserAgentGroups:  1,
+				UserAgents:       []string{"*"},
+				Sitemaps:         []string{},
+				Warnings: []util.RobotsTxtWarning{
+					{Message: "robots.txt exceeds the size limit. The content after the limit is ignored",
+						Severity: util.SeverityWarning},
+					{Line: 1, Column: 1, Text: "Disallow: /before-agent",
+						Message: "disallow rule before any user-agent is ignored", Severity: util.SeverityError},
+					{Line: 3, Column: 11, Text: "Disallow: private",
+						Message: "disallow path should start with '/' or '*'", Severity: util.SeverityWarning},
+				},
+				Redirects: []string{},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "robots.txt not found",
+			url:                  "https://example.com/test",
+			mockHttpResponseCode: http.StatusNotFound,
+			expectedAudit: &model.RobotsAudit{
+				Url:        "https://example.com/test",
+				Reachable:  true,
+				StatusCode: http.StatusNotFound,
+				MaxSize:    1024,
+				UserAgents: []string{},
+				Sitemaps:   []string{},
+				Warnings:   []util.RobotsTxtWarning{},
+				Redirects:  []string{},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			httpMock := httptest.NewRecorder()
+			httpMock.WriteString(test.mockHttpResponseBody)
+			httpMock.Code = test.mockHttpResponseCode
+			response := httpMock.Result()
+			response.ContentLength = int64(len(test.mockHttpResponseBody))
+			httpClient := &http.Client{Transport: &mockRoundTripper{response}}
+			cfg := testConfig()
+			cfg.HttpClientSettings.MaxRobotsSize = 1
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, nil, nil, nil, httpClient)
+			r.POST("/audit", robotsHandler.AuditRobotsTxt)
+			req, _ := http.NewRequest("POST", "/audit?url="+test.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var audit model.RobotsAudit
+			assert.NoError(tt, json.Unmarshal(w.Body.Bytes(), &audit))
+			assert.Equal(tt, test.expectedAudit, &audit)
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_AuditRobotsTxt_Redirects_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	locations := map[string]string{
+		"https://example.com/robots.txt":     "https://www.example.com/robots.txt",
+		"https://www.example.com/robots.txt": "/new/robots.txt",
+	}
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if location, ok := locations[req.URL.String()]; ok {
+			return &http.Response{
+				StatusCode: http.StatusMovedPermanently,
+				Header:     http.Header{"Location": {location}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Body:          io.NopCloser(strings.NewReader("User-agent: *\nAllow: /\n")),
+			ContentLength: 24,
+			Request:       req,
+		}, nil
+	})}
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), nil, nil, nil, httpClient)
+	r.POST("/audit", robotsHandler.AuditRobotsTxt)
+	req, _ := http.NewRequest("POST", "/audit?url=https://example.com/test", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var audit model.RobotsAudit
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &audit))
+	assert.Equal(t, []string{
+		"https://example.com/robots.txt",
+		"https://www.example.com/robots.txt",
+		"https://www.example.com/new/robots.txt",
+	}, audit.Redirects)
+	assert.Equal(t, http.StatusOK, audit.StatusCode)
+	assert.Equal(t, 1, audit.UserAgentGroups)
+}
+
+func Test_AuditRobotsTxt_MissingUrl_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), nil, nil, nil, nil)
+	r.POST("/audit", robotsHandler.AuditRobotsTxt)
+	req, _ := http.NewRequest("POST", "/audit", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "{\"error\":\"'url' query parameter is required\"}", w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/IliaW/robots-api/internal/metrics"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// Batch error codes of the url decisions.
+const (
+	batchErrorInvalidUrl = "invalid_url"
+	batchErrorLoadFailed = "load_failed"
+)
+
+// GetAllowedBatch godoc
+// @Summary Check if scraping is allowed for many urls of any domains
+// @Description Check every url for the user agent. The urls are grouped by the origin internally, so the robots.txt
+// @Description rules of every origin are resolved once, but the results are returned in the order of the request urls,
+// @Description duplicates included, so they can be matched to the urls by index. The number of urls is limited by
+// @Description 'robots.max_paths'. Up to 'robots.batch_concurrency' origins are resolved concurrently.
+// @Description A failure of one url (invalid url or robots.txt load error) doesn't fail the batch
+// @Tags Scraping
+// @Accept json
+// @Produce json
+// @Param request body model.BatchCheckRequest true "User agent and urls to check"
+// @Success 200 {object} model.BatchCheckResult "Batch summary and decision of every url in the request order"
+// @Failure 400 {object} error "Bad request, invalid body, no or too many urls, missing 'user_agent' or the user agent is not in the allowlist"
+// @Security ApiKeyAuth
+// @Router /scrape-allowed/batch [post]
+func (h *RobotsHandler) GetAllowedBatch(c *gin.Context) {
+	var request model.BatchCheckRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body. %s", err.Error())})
+		return
+	}
+	userAgent, err := h.bodyUserAgent(request.UserAgent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Urls) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'urls' must not be empty"})
+		return
+	}
+	if maxUrls := h.maxPaths(); len(request.Urls) > maxUrls {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many urls. Max is %d", maxUrls)})
+		return
+	}
+
+	// the results are written by the index of the url, so the grouping by origin doesn't change the order
+	result := &model.BatchCheckResult{
+		BatchSummary: model.NewBatchSummary(len(request.Urls)),
+		Results:      make([]model.UrlDecision, len(request.Urls)),
+	}
+	errorCodes := make([]string, len(request.Urls))
+	var origins []string
+	groups := make(map[string][]int)
+	for i, rawUrl := range request.Urls {
+		result.Results[i].Url = rawUrl
+		baseUrl, err := h.batchBaseUrl(rawUrl)
+		if err != nil {
+			result.Results[i].Error = err.Error()
+			errorCodes[i] = batchErrorInvalidUrl
+			continue
+		}
+		if _, ok := groups[baseUrl]; !ok {
+			origins = append(origins, baseUrl)
+		}
+		groups[baseUrl] = append(groups[baseUrl], i)
+	}
+	// the origins are resolved by a bounded pool of workers. Every origin writes only the results of its urls,
+	// so the workers don't share the results
+	ctx := c.Request.Context()
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(h.batchConcurrency(), len(origins)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for baseUrl := range jobs {
+				h.decideOrigin(ctx, baseUrl, userAgent, request.Urls, groups[baseUrl], result.Results, errorCodes)
+			}
+		}()
+	}
+	for _, baseUrl := range origins {
+		jobs <- baseUrl
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, code := range errorCodes {
+		if code != "" {
+			result.AddError(i, code, result.Results[i].Error)
+		}
+	}
+	slog.Debug("scrape check of the batch decided.", slog.Int("urls", result.Total),
+		slog.Int("origins", len(origins)), slog.Int("failed", result.Failed))
+
+	c.JSON(http.StatusOK, result)
+}
+
+// decideOrigin resolves the robots.txt rules of the origin and decides its urls by the indexes. The origin is not
+// requested if the request is canceled. The custom rule is read by GetByUrlBulk.
+func (h *RobotsHandler) decideOrigin(ctx context.Context, baseUrl, userAgent string, urls []string, indexes []int,
+	results []model.UrlDecision, errorCodes []string) {
+	err := ctx.Err()
+	resolved := &resolvedRobotsTxt{}
+	if err == nil {
+		resolved, err = h.resolveAgent(h.ruleRepo.GetByUrlBulk, baseUrl+"/", userAgent)
+	}
+	if err != nil {
+		slog.Warn("failed to load robots.txt of the batch urls.", slog.String("url", baseUrl),
+			slog.String("err", err.Error()))
+		for _, i := range indexes {
+			results[i].Error = fmt.Sprintf("failed to load robots.txt. %s", err.Error())
+			errorCodes[i] = batchErrorLoadFailed
+		}
+		return
+	}
+	metrics.ScrapeDecisions.WithLabelValues(decisionSource(resolved.status)).Add(float64(len(indexes)))
+	for _, i := range indexes {
+		results[i].Allowed = h.matcher.AgentAllowed(resolved.robotsTxt, userAgent, h.batchUrl(urls[i]))
+	}
+}
+
+// batchConcurrency returns the max number of origins resolved concurrently by one batch request.
+func (h *RobotsHandler) batchConcurrency() int {
+	if concurrency := h.cfg.RobotsSettings.BatchConcurrency; concurrency > 1 {
+		return concurrency
+	}
+	return 1
+}
+
+// batchUrl normalizes the batch url the same way as the 'url' query parameter of the other endpoints,
+// including 'robots.assume_scheme' for the url without a scheme.
+func (h *RobotsHandler) batchUrl(rawUrl string) string {
+	return util.NormalizeUrl(util.AssumeScheme(strings.TrimSpace(rawUrl), h.cfg.RobotsSettings.AssumeScheme))
+}
+
+// batchBaseUrl returns the base url of the batch url, that groups the urls with the same robots.txt.
+// A url with credentials is rejected unless 'robots.strip_userinfo' is enabled.
+func (h *RobotsHandler) batchBaseUrl(rawUrl string) (string, error) {
+	url := h.batchUrl(rawUrl)
+	if util.HasUserinfo(url) && !h.cfg.RobotsSettings.StripUserinfo {
+		return "", util.ErrUrlUserinfo
+	}
+	return util.GetBaseUrl(url)
+}
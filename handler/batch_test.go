@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetAllowedBatch_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		body               string
+		mockOrigins        bool
+		maxUrls            int
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name: "results in the order of the urls",
+			body: `{"user_agent":"mybot","urls":["https://a.com/public","https://b.com/x","https://a.com/private",` +
+				`"https://a.com/public","not a url","https://b.com/x","https://c.com/x"]}`,
+			mockOrigins: true,
+			expectedResponse: `{"total":7,"succeeded":5,"failed":2,"errors":[` +
+				`{"index":4,"code":"invalid_url","message":"invalid url. Url should contain scheme and hostname"},` +
+				`{"index":6,"code":"load_failed","message":"failed to load robots.txt. Get \"https://c.com/robots.txt\": connection refused"}],` +
+				`"results":[{"url":"https://a.com/public","allowed":true},{"url":"https://b.com/x","allowed":false},` +
+				`{"url":"https://a.com/private","allowed":false},{"url":"https://a.com/public","allowed":true},` +
+				`{"url":"not a url","allowed":false,"error":"invalid url. Url should contain scheme and hostname"},` +
+				`{"url":"https://b.com/x","allowed":false},` +
+				`{"url":"https://c.com/x","allowed":false,"error":"failed to load robots.txt. Get \"https://c.com/robots.txt\": connection refused"}]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "no urls",
+			body:               `{"user_agent":"mybot","urls":[]}`,
+			expectedResponse:   `{"error":"'urls' must not be empty"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "too many urls",
+			body:               `{"user_agent":"mybot","urls":["https://a.com/1","https://a.com/2","https://a.com/3"]}`,
+			maxUrls:            2,
+			expectedResponse:   `{"error":"too many urls. Max is 2"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "missing user agent",
+			body:               `{"urls":["https://a.com/1"]}`,
+			expectedResponse:   `{"error":"'user_agent' is required"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			if test.mockOrigins {
+				// every origin is resolved once
+				cache.On("GetRobotsFile", "https://a.com/").
+					Return("User-agent: *\nDisallow: /private", time.Hour, true).Once()
+				cache.On("GetRobotsFile", "https://b.com/").Return("User-agent: *\nDisallow: /", time.Hour, true).Once()
+				cache.On("GetRobotsFile", "https://c.com/").Return("", time.Duration(0), false).Once()
+			}
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrlBulk", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+			httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			})}
+			cfg := testConfig()
+			cfg.RobotsSettings.MaxPaths = test.maxUrls
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, httpClient)
+			r.POST("/scrape-allowed/batch", robotsHandler.GetAllowedBatch)
+			req, _ := http.NewRequest("POST", "/scrape-allowed/batch", strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_GetAllowedBatch_AssumeScheme_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name             string
+		assumeScheme     string
+		expectedResponse string
+	}{
+		{
+			name:         "scheme is prepended to the url without a scheme",
+			assumeScheme: "https",
+			expectedResponse: `{"total":2,"succeeded":2,"failed":0,"errors":[],"results":[` +
+				`{"url":" Example.com/private","allowed":false},{"url":"example.com/public","allowed":true}]}`,
+		},
+		{
+			name: "url without a scheme is invalid by default",
+			expectedResponse: `{"total":2,"succeeded":0,"failed":2,"errors":[` +
+				`{"index":0,"code":"invalid_url","message":"invalid url. Url should contain scheme and hostname"},` +
+				`{"index":1,"code":"invalid_url","message":"invalid url. Url should contain scheme and hostname"}],` +
+				`"results":[{"url":" Example.com/private","allowed":false,` +
+				`"error":"invalid url. Url should contain scheme and hostname"},` +
+				`{"url":"example.com/public","allowed":false,` +
+				`"error":"invalid url. Url should contain scheme and hostname"}]}`,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", "https://example.com/").Maybe().
+				Return("User-agent: *\nDisallow: /private", time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrlBulk", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+			cfg := testConfig()
+			cfg.RobotsSettings.AssumeScheme = test.assumeScheme
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, nil)
+			r.POST("/scrape-allowed/batch", robotsHandler.GetAllowedBatch)
+			req, _ := http.NewRequest("POST", "/scrape-allowed/batch", strings.NewReader(
+				`{"user_agent":"mybot","urls":[" Example.com/private","example.com/public"]}`))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, http.StatusOK, w.Code)
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func Test_GetAllowedBatch_Concurrency_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const concurrency = 2
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", mock.Anything).Return("", time.Duration(0), false)
+	cache.On("SaveRobotsFile", mock.Anything, mock.Anything)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrlBulk", mock.Anything).Return(nil, persistence.ErrNotFound)
+	var inFlight, maxInFlight atomic.Int32
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		// wait for the concurrent fetch, so the fetches overlap
+		for deadline := time.Now().Add(time.Second); maxInFlight.Load() < concurrency && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(10 * time.Millisecond)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("User-agent: *\nDisallow: /private")),
+		}, nil
+	})}
+	cfg := testConfig()
+	cfg.RobotsSettings.BatchConcurrency = concurrency
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, httpClient)
+	r.POST("/scrape-allowed/batch", robotsHandler.GetAllowedBatch)
+	req, _ := http.NewRequest("POST", "/scrape-allowed/batch", strings.NewReader(`{"user_agent":"mybot","urls":[`+
+		`"https://a.com/x","https://b.com/private","https://c.com/x","https://d.com/private","https://e.com/x"]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"total":5,"succeeded":5,"failed":0,"errors":[]`)
+	assert.Contains(t, w.Body.String(), `"results":[{"url":"https://a.com/x","allowed":true},`+
+		`{"url":"https://b.com/private","allowed":false},{"url":"https://c.com/x","allowed":true},`+
+		`{"url":"https://d.com/private","allowed":false},{"url":"https://e.com/x","allowed":true}]`)
+	assert.Equal(t, int32(concurrency), maxInFlight.Load())
+}
+
+func Test_GetAllowedBatch_CanceledRequest_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	robotsHandler := NewRobotsHandler(testConfig(), nil, ruleRepo, nil, nil)
+	r := gin.Default()
+	r.POST("/scrape-allowed/batch", robotsHandler.GetAllowedBatch)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/scrape-allowed/batch",
+		strings.NewReader(`{"user_agent":"mybot","urls":["https://a.com/x"]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// the origin is not requested after the cancellation
+	assert.Equal(t, `{"total":1,"succeeded":0,"failed":1,"errors":[{"index":0,"code":"load_failed",`+
+		`"message":"failed to load robots.txt. context canceled"}],"results":[{"url":"https://a.com/x",`+
+		`"allowed":false,"error":"failed to load robots.txt. context canceled"}]}`, w.Body.String())
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxInvalidateEntries = 100
+
+// GetCacheServers godoc
+// @Summary Get cache servers
+// @Description Retrieve the configured cache servers and their reachability from the periodic health check
+// @Tags Cache
+// @Produce json
+// @Success 200 {array} model.CacheServer "Cache servers"
+// @Security ApiKeyAuth
+// @Router /cache/servers [get]
+func (h *RobotsHandler) GetCacheServers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Servers())
+}
+
+// InvalidateCache godoc
+// @Summary Invalidate cached robots.txt files
+// @Description Delete cached robots.txt files for the given urls and domains. The result is reported for every entry
+// @Description with the batch summary. The error index is the position of the entry in the urls followed by the domains.
+// @Tags Cache
+// @Accept json
+// @Produce json
+// @Param request body model.CacheInvalidationRequest true "Urls and domains to invalidate"
+// @Success 200 {object} model.CacheInvalidationSummary "Batch summary and invalidation result of every entry"
+// @Failure 400 {object} error "Bad request, invalid body, no entries or too many entries"
+// @Security ApiKeyAuth
+// @Router /cache/invalidate [post]
+func (h *RobotsHandler) InvalidateCache(c *gin.Context) {
+	var request model.CacheInvalidationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body. %s", err.Error())})
+		return
+	}
+	entries := len(request.Urls) + len(request.Domains)
+	if entries == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'urls' or 'domains' must not be empty"})
+		return
+	}
+	if maxEntries := h.maxInvalidateEntries(); entries > maxEntries {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many entries. Max is %d", maxEntries)})
+		return
+	}
+
+	summary := &model.CacheInvalidationSummary{
+		BatchSummary: model.NewBatchSummary(entries),
+		Results:      make([]model.CacheInvalidation, 0, entries),
+	}
+	for _, url := range request.Urls {
+		addInvalidation(summary, h.invalidate(url, url))
+	}
+	for _, domain := range request.Domains {
+		addInvalidation(summary, h.invalidate(domain, "https://"+domain))
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *RobotsHandler) maxInvalidateEntries() int {
+	if limit := h.cfg.CacheSettings.MaxInvalidateEntries; limit > 0 {
+		return limit
+	}
+	return defaultMaxInvalidateEntries
+}
+
+// RefreshCache godoc
+// @Summary Refresh the cached robots.txt file
+// @Description Fetch the robots.txt file of the url from origin bypassing the cache and save it to cache,
+// @Description e.g. to pre-warm the cache or to apply a changed robots.txt before the cached file expires
+// @Tags Cache
+// @Produce json
+// @Param url query string true "URL of the site"
+// @Success 200 {object} model.CacheRefresh "Origin status code and size of the fetched robots.txt"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt"
+// @Failure 400 {object} error "Bad request, missing 'url' or url with credentials"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /cache/refresh [post]
+func (h *RobotsHandler) RefreshCache(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+
+	fetched, err := h.fetchOrigin(url)
+	robotsTxt, status := fetched.robotsTxt, fetched.status
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to refresh robots.txt. %s", err.Error())})
+		return
+	}
+	slog.Info("cached robots.txt is refreshed.", slog.String("url", url), slog.String("status", status))
+
+	c.JSON(http.StatusOK, &model.CacheRefresh{Url: url, Status: status, Size: len(robotsTxt)})
+}
+
+// batchErrorInvalidateFailed is the batch error code of the entry that couldn't be deleted from cache.
+const batchErrorInvalidateFailed = "invalidate_failed"
+
+// addInvalidation adds the result of the entry to the summary. The index of the failed entry is its position
+// in the results.
+func addInvalidation(summary *model.CacheInvalidationSummary, result model.CacheInvalidation) {
+	if !result.Invalidated {
+		summary.AddError(len(summary.Results), batchErrorInvalidateFailed, result.Error)
+	}
+	summary.Results = append(summary.Results, result)
+}
+
+func (h *RobotsHandler) invalidate(entry, url string) model.CacheInvalidation {
+	result := model.CacheInvalidation{Entry: entry, Invalidated: true}
+	if err := h.cache.Invalidate(url); err != nil {
+		result.Invalidated = false
+		result.Error = err.Error()
+	}
+	return result
+}
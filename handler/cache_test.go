@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetCacheServers_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	checked := time.Date(2024, 11, 4, 10, 0, 0, 0, time.UTC)
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("Servers").Return([]model.CacheServer{
+		{Address: "cache1:11211", Reachable: true, LastChecked: checked},
+		{Address: "cache2:11211", Reachable: false, Error: "connection refused", LastChecked: checked},
+	})
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), cache, nil, nil, nil)
+	r.GET("/cache/servers", robotsHandler.GetCacheServers)
+	req, _ := http.NewRequest("GET", "/cache/servers", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	responseData, _ := io.ReadAll(w.Body)
+	assert.Equal(t, "[{\"address\":\"cache1:11211\",\"reachable\":true,\"last_checked\":\"2024-11-04T10:00:00Z\"},"+
+		"{\"address\":\"cache2:11211\",\"reachable\":false,\"error\":\"connection refused\","+
+		"\"last_checked\":\"2024-11-04T10:00:00Z\"}]", string(responseData))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func Test_InvalidateCache_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		body               string
+		mockInvalidate     map[string]error
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name: "invalidate several domains and urls",
+			body: "{\"urls\":[\"https://example.com/test\"],\"domains\":[\"example.org\",\"example.net\"]}",
+			mockInvalidate: map[string]error{
+				"https://example.com/test": nil,
+				"https://example.org":      nil,
+				"https://example.net":      errors.New("connection refused"),
+			},
+			expectedResponse: "{\"total\":3,\"succeeded\":2,\"failed\":1,\"errors\":[{\"index\":2," +
+				"\"code\":\"invalidate_failed\",\"message\":\"connection refused\"}]," +
+				"\"results\":[{\"entry\":\"https://example.com/test\",\"invalidated\":true}," +
+				"{\"entry\":\"example.org\",\"invalidated\":true}," +
+				"{\"entry\":\"example.net\",\"invalidated\":false,\"error\":\"connection refused\"}]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "mixed batch with several failures",
+			body: "{\"urls\":[\"https://a.com/x\",\"https://b.com/y\"],\"domains\":[\"c.com\"]}",
+			mockInvalidate: map[string]error{
+				"https://a.com/x": errors.New("server error"),
+				"https://b.com/y": nil,
+				"https://c.com":   errors.New("connection refused"),
+			},
+			expectedResponse: "{\"total\":3,\"succeeded\":1,\"failed\":2,\"errors\":[" +
+				"{\"index\":0,\"code\":\"invalidate_failed\",\"message\":\"server error\"}," +
+				"{\"index\":2,\"code\":\"invalidate_failed\",\"message\":\"connection refused\"}]," +
+				"\"results\":[{\"entry\":\"https://a.com/x\",\"invalidated\":false,\"error\":\"server error\"}," +
+				"{\"entry\":\"https://b.com/y\",\"invalidated\":true}," +
+				"{\"entry\":\"c.com\",\"invalidated\":false,\"error\":\"connection refused\"}]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "no entries",
+			body:               "{\"domains\":[]}",
+			expectedResponse:   "{\"error\":\"'urls' or 'domains' must not be empty\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "too many entries",
+			body:               "{\"domains\":[\"a.com\",\"b.com\",\"c.com\",\"d.com\"]}",
+			expectedResponse:   "{\"error\":\"too many entries. Max is 3\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			for url, err := range test.mockInvalidate {
+				cache.On("Invalidate", url).Return(err).Once()
+			}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), cache, nil, nil, nil)
+			r.POST("/cache/invalidate", robotsHandler.InvalidateCache)
+			req, _ := http.NewRequest("POST", "/cache/invalidate", strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_InvalidateCache_DefaultMaxEntries_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("Invalidate", "https://example.com").Return(nil).Once()
+	cfg := testConfig()
+	cfg.CacheSettings.MaxInvalidateEntries = 0
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(cfg, cache, nil, nil, nil)
+	r.POST("/cache/invalidate", robotsHandler.InvalidateCache)
+	req, _ := http.NewRequest("POST", "/cache/invalidate", strings.NewReader("{\"domains\":[\"example.com\"]}"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{\"total\":1,\"succeeded\":1,\"failed\":0,\"errors\":[],"+
+		"\"results\":[{\"entry\":\"example.com\",\"invalidated\":true}]}", w.Body.String())
+}
+
+func Test_RefreshCache_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		url                string
+		mockCacheWrite     bool
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "fetch from origin and save to cache",
+			url:                "https://example.com/test",
+			mockCacheWrite:     true,
+			expectedResponse:   "{\"url\":\"https://example.com/test\",\"status\":\"200\",\"size\":25}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missed url in query",
+			url:                "",
+			expectedResponse:   "{\"error\":\"'url' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			// the cache is not read, so an unexpected GetRobotsFile call fails the test
+			cache := cacheMock.NewCachedClient(tt)
+			if test.mockCacheWrite {
+				cache.On("SaveRobotsFile", test.url, []byte("User-agent: *\nDisallow: /")).Return().Once()
+			}
+			fetched := 0
+			httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				fetched++
+				assert.Equal(tt, "https://example.com/robots.txt", req.URL.String())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("User-agent: *\nDisallow: /")),
+				}, nil
+			})}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), cache, nil, nil, httpClient)
+			r.POST("/cache/refresh", robotsHandler.RefreshCache)
+			req, _ := http.NewRequest("POST", "/cache/refresh?url="+test.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			if test.mockCacheWrite {
+				assert.Equal(tt, 1, fetched)
+				assert.Equal(tt, "200", w.Header().Get(robotsStatusHeader))
+			}
+		})
+	}
+}
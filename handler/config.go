@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/gin-gonic/gin"
+)
+
+// GetEffectiveConfig godoc
+// @Summary Get effective configuration
+// @Description Retrieve the loaded configuration, including the environment overrides. Secrets are redacted
+// @Tags Config
+// @Produce json
+// @Success 200 {object} map[string]any "Effective configuration"
+// @Security ApiKeyAuth
+// @Router /config/effective [get]
+func (h *RobotsHandler) GetEffectiveConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cfg.Effective())
+}
+
+// GetConfigSources godoc
+// @Summary Get the source of every configuration setting
+// @Description Report whether every setting comes from an environment variable ('env'), the config file ('file')
+// @Description or is not set ('default'), e.g. to find out why a setting doesn't take effect. Secrets are redacted
+// @Tags Config
+// @Produce json
+// @Success 200 {object} map[string]string "Source of every setting keyed by the dotted name, e.g. 'database.port'"
+// @Security ApiKeyAuth
+// @Router /config/sources [get]
+func (h *RobotsHandler) GetConfigSources(c *gin.Context) {
+	c.JSON(http.StatusOK, config.Sources())
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetEffectiveConfig_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := testConfig()
+	cfg.CacheSettings.TtlForRobotsTxt = 24 * time.Hour
+	cfg.DbSettings = &config.DatabaseConfig{User: "admin", Password: "secret"}
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(cfg, nil, nil, nil, nil)
+	r.GET("/config/effective", robotsHandler.GetEffectiveConfig)
+	req, _ := http.NewRequest("GET", "/config/effective", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ttl_for_robots_txt":"24h0m0s"`)
+	assert.Contains(t, w.Body.String(), `"password":"[REDACTED]"`)
+	assert.NotContains(t, w.Body.String(), "secret")
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// DbStatsProvider reports the stats of the database connection pool, e.g. *sql.DB.
+type DbStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// DebugHandler serves the runtime stats for debugging leaks, lighter than pprof.
+type DebugHandler struct {
+	db DbStatsProvider
+}
+
+func NewDebugHandler(db DbStatsProvider) *DebugHandler {
+	return &DebugHandler{db: db}
+}
+
+// GetDebugStats godoc
+// @Summary Get the goroutine, memory and database connection stats
+// @Description Report the number of goroutines, heap and GC stats and the database connection pool stats,
+// @Description e.g. to spot goroutine or connection leaks. Reading the memory stats briefly stops the world
+// @Tags Debug
+// @Produce json
+// @Success 200 {object} model.DebugStats "Runtime and database connection stats"
+// @Security ApiKeyAuth
+// @Router /debug/stats [get]
+func (h *DebugHandler) GetDebugStats(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := &model.DebugStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapObjects:    mem.HeapObjects,
+		SysBytes:       mem.Sys,
+		GcCycles:       mem.NumGC,
+		GcPauseTotalMs: time.Duration(mem.PauseTotalNs).Milliseconds(),
+	}
+	if mem.LastGC > 0 {
+		stats.LastGcUnix = time.Unix(0, int64(mem.LastGC)).Unix()
+	}
+	if h.db != nil {
+		dbStats := h.db.Stats()
+		stats.DbOpenConnections = dbStats.OpenConnections
+		stats.DbInUse = dbStats.InUse
+		stats.DbIdle = dbStats.Idle
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
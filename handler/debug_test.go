@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDbStats struct {
+	stats sql.DBStats
+}
+
+func (f *fakeDbStats) Stats() sql.DBStats { return f.stats }
+
+func Test_GetDebugStats_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := &fakeDbStats{stats: sql.DBStats{OpenConnections: 3, InUse: 1, Idle: 2}}
+
+	r := gin.Default()
+	r.GET("/debug/stats", NewDebugHandler(db).GetDebugStats)
+	req, _ := http.NewRequest("GET", "/debug/stats", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "\"goroutines\":")
+	var stats model.DebugStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Positive(t, stats.Goroutines)
+	assert.Positive(t, stats.HeapAllocBytes)
+	assert.Equal(t, 3, stats.DbOpenConnections)
+	assert.Equal(t, 1, stats.DbInUse)
+	assert.Equal(t, 2, stats.DbIdle)
+}
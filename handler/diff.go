@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/internal/persistence"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCustomRuleDiff godoc
+// @Summary Compare a custom rule with the origin robots.txt
+// @Description Fetch the live robots.txt file of the url and compare it line by line with the custom rule of the
+// @Description domain, e.g. to find the stale custom rules. The cache is not used and not changed.
+// @Tags Custom Rule
+// @Produce json
+// @Param url query string true "URL of the site"
+// @Success 200 {object} model.RuleDiff "Line-level diff and whether the content is identical"
+// @Header 200,500,502 {string} X-Robots-Status "Origin status code of robots.txt"
+// @Failure 400 {object} error "Bad request, missing 'url' or url with credentials"
+// @Failure 404 {object} error "Rule not found"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 502 {object} error "Origin responded without robots.txt, e.g. with 404 or an empty file"
+// @Failure 503 {object} error "Database is unavailable or origin is rate limiting robots.txt requests"
+// @Security ApiKeyAuth
+// @Router /custom-rule/diff [get]
+func (h *RobotsHandler) GetCustomRuleDiff(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetByUrl(url)
+	if err != nil {
+		status := http.StatusNotFound
+		if persistence.IsUnavailable(err) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": fmt.Sprintf("failed to get rule by url. %s", err.Error())})
+		return
+	}
+
+	robotsTxt, sourceUrl, status, err := h.fetchLiveRobotsTxt(url)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(liveRobotsTxtErrorStatus(c, err),
+			gin.H{"error": fmt.Sprintf("failed to fetch robots.txt. %s", err.Error())})
+		return
+	}
+
+	ruleDiff := &model.RuleDiff{RuleID: rule.ID, SourceUrl: sourceUrl, Identical: true}
+	for _, line := range util.DiffLines(rule.RobotsTxt, robotsTxt) {
+		if line.Op != util.DiffEqual {
+			ruleDiff.Identical = false
+		}
+		ruleDiff.Diff = append(ruleDiff.Diff, model.DiffLine{Op: line.Op, Line: line.Line})
+	}
+
+	c.JSON(http.StatusOK, ruleDiff)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetCustomRuleDiff_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name                 string
+		rule                 *model.Rule
+		ruleErr              error
+		mockHttpResponseBody string
+		expectedResponse     string
+		expectedStatusCode   int
+	}{
+		{
+			name:                 "identical content",
+			rule:                 &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /private\n"},
+			mockHttpResponseBody: "User-agent: *\r\nDisallow: /private",
+			expectedResponse: "{\"rule_id\":1,\"source_url\":\"https://example.com/robots.txt\",\"identical\":true," +
+				"\"diff\":[{\"op\":\"equal\",\"line\":\"User-agent: *\"},{\"op\":\"equal\",\"line\":\"Disallow: /private\"}]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "divergent content",
+			rule:                 &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /private"},
+			mockHttpResponseBody: "User-agent: *\nDisallow: /admin",
+			expectedResponse: "{\"rule_id\":1,\"source_url\":\"https://example.com/robots.txt\",\"identical\":false," +
+				"\"diff\":[{\"op\":\"equal\",\"line\":\"User-agent: *\"},{\"op\":\"removed\",\"line\":\"Disallow: /private\"}," +
+				"{\"op\":\"added\",\"line\":\"Disallow: /admin\"}]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "rule not found",
+			ruleErr:            fmt.Errorf("rule with domain 'example.com' %w", persistence.ErrNotFound),
+			expectedResponse:   "{\"error\":\"failed to get rule by url. rule with domain 'example.com' not found\"}",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Return(test.rule, test.ruleErr)
+			httpMock := httptest.NewRecorder()
+			httpMock.WriteString(test.mockHttpResponseBody)
+			httpClient := &http.Client{Transport: &mockRoundTripper{httpMock.Result()}}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), nil, ruleRepo, nil, httpClient)
+			r.GET("/custom-rule/diff", robotsHandler.GetCustomRuleDiff)
+			req, _ := http.NewRequest("GET", "/custom-rule/diff?url=https://example.com/page", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDomainsLimit = 100
+	maxDomainsLimit     = 1000
+)
+
+// GetCustomRuleDomains godoc
+// @Summary List the domains with custom rules
+// @Description Retrieve the ids and domains of the custom rules ordered by the domain, without the rule content
+// @Tags Custom Rule
+// @Produce json
+// @Param limit query int false "Max number of domains, 100 by default and 1000 at most"
+// @Param offset query int false "Number of domains to skip"
+// @Success 200 {array} model.RuleDomain "Ids and domains of the custom rules"
+// @Failure 400 {object} error "Bad request, invalid 'limit' or 'offset'"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Database is unavailable"
+// @Security ApiKeyAuth
+// @Router /custom-rule/domains [get]
+func (h *RobotsHandler) GetCustomRuleDomains(c *gin.Context) {
+	limit, err := queryInt(c, "limit", defaultDomainsLimit)
+	if err != nil || limit < 1 || limit > maxDomainsLimit {
+		c.JSON(http.StatusBadRequest,
+			gin.H{"error": fmt.Sprintf("'limit' query parameter must be between 1 and %d", maxDomainsLimit)})
+		return
+	}
+	offset, err := queryInt(c, "offset", 0)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'offset' query parameter must be a non-negative number"})
+		return
+	}
+
+	domains, err := h.ruleRepo.ListDomains(limit, offset)
+	if err != nil {
+		c.JSON(dbErrorStatus(err), gin.H{"error": fmt.Sprintf("failed to list custom rule domains. %s", err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, domains)
+}
+
+// queryInt returns the integer query parameter or the default value if it is not sent.
+func queryInt(c *gin.Context, name string, defaultValue int) (int, error) {
+	value, ok := c.GetQuery(name)
+	if !ok {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
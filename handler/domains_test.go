@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IliaW/robots-api/internal/model"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetCustomRuleDomains_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		query              string
+		mockLimit          int
+		mockOffset         int
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "default pagination",
+			query:              "",
+			mockLimit:          100,
+			mockOffset:         0,
+			expectedResponse:   "[{\"id\":2,\"domain\":\"example.com\"},{\"id\":1,\"domain\":\"example.org\"}]",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "limit and offset",
+			query:              "?limit=2&offset=4",
+			mockLimit:          2,
+			mockOffset:         4,
+			expectedResponse:   "[{\"id\":2,\"domain\":\"example.com\"},{\"id\":1,\"domain\":\"example.org\"}]",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "limit over the max",
+			query:              "?limit=1001",
+			expectedResponse:   "{\"error\":\"'limit' query parameter must be between 1 and 1000\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "negative offset",
+			query:              "?offset=-1",
+			expectedResponse:   "{\"error\":\"'offset' query parameter must be a non-negative number\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			if test.mockLimit != 0 {
+				ruleRepo.On("ListDomains", test.mockLimit, test.mockOffset).Return([]*model.RuleDomain{
+					{ID: 2, Domain: "example.com"},
+					{ID: 1, Domain: "example.org"},
+				}, nil)
+			}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), nil, ruleRepo, nil, nil)
+			r.GET("/custom-rule/domains", robotsHandler.GetCustomRuleDomains)
+			req, _ := http.NewRequest("GET", "/custom-rule/domains"+test.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// robotsSourceHeader reports where the rules of the effective robots.txt came from:
+// 'custom', 'cache' or 'origin'.
+const robotsSourceHeader = "X-Robots-Source"
+
+// robotsTruncatedHeader is set when the effective robots.txt is longer than 'http_client.max_robots_size'.
+const robotsTruncatedHeader = "X-Robots-Truncated"
+
+// robotsLengthHeader and robotsHashHeader report the length in bytes and the sha256 hash of the resolved robots.txt,
+// so clients can correlate the responses and detect changed rules between polls.
+const (
+	robotsLengthHeader = "X-Robots-Length"
+	robotsHashHeader   = "X-Robots-Hash"
+)
+
+// GetEffectiveRobots godoc
+// @Summary Get the robots.txt content used for the scrape check of the url
+// @Description Resolve the rules the same way as the scrape check (custom rule, cache or origin) and return
+// @Description the robots.txt content. The content is limited by 'http_client.max_robots_size'
+// @Tags Scraping
+// @Produce plain
+// @Param url query string true "URL to check"
+// @Success 200 {string} string "robots.txt content"
+// @Header 200 {string} X-Robots-Source "Source of the rules: 'custom', 'cache' or 'origin'"
+// @Header 200 {string} X-Robots-Truncated "'true' if the content is truncated"
+// @Header 200 {integer} X-Robots-Length "Length of the resolved robots.txt in bytes, before the truncation"
+// @Header 200 {string} X-Robots-Hash "Hex sha256 hash of the resolved robots.txt, before the truncation"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, missing 'url' or url with credentials"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /effective-robots [get]
+func (h *RobotsHandler) GetEffectiveRobots(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+
+	robotsTxt, status, err := h.resolveRobotsTxt(url)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+
+	setContentHeaders(c, robotsTxt)
+	// the origin robots.txt is already limited on fetch, but a custom rule may be longer
+	if limit := h.maxRobotsSize(); limit > 0 && int64(len(robotsTxt)) > limit {
+		robotsTxt = robotsTxt[:limit]
+		c.Header(robotsTruncatedHeader, "true")
+	}
+	c.Header(robotsSourceHeader, decisionSource(status))
+	c.String(http.StatusOK, robotsTxt)
+}
+
+// setContentHeaders sets the length and the sha256 hash of the resolved robots.txt.
+func setContentHeaders(c *gin.Context, robotsTxt string) {
+	hash := sha256.Sum256([]byte(robotsTxt))
+	c.Header(robotsLengthHeader, strconv.Itoa(len(robotsTxt)))
+	c.Header(robotsHashHeader, hex.EncodeToString(hash[:]))
+}
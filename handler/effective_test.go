@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetEffectiveRobots_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	largeRule := "User-agent: *\n" + strings.Repeat("Disallow: /private\n", 100)
+	testSet := []struct {
+		name               string
+		customRule         string
+		originRobotsTxt    string
+		expectedResponse   string
+		expectedSource     string
+		expectedStatus     string
+		expectedTruncated  string
+		expectedLength     string
+		expectedHash       string
+		expectedStatusCode int
+	}{
+		{
+			name:               "custom rule",
+			customRule:         "User-agent: *\nDisallow: /custom",
+			expectedResponse:   "User-agent: *\nDisallow: /custom",
+			expectedSource:     "custom",
+			expectedStatus:     "custom",
+			expectedLength:     "31",
+			expectedHash:       "ce0751d21b12b800f75dfcdef797cecfab92a37ff5c8daa7e26344180622a544",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "custom rule longer than the limit is truncated",
+			customRule:         largeRule,
+			expectedResponse:   largeRule[:1024],
+			expectedSource:     "custom",
+			expectedStatus:     "custom",
+			expectedTruncated:  "true",
+			expectedLength:     strconv.Itoa(len(largeRule)),
+			expectedHash:       fmt.Sprintf("%x", sha256.Sum256([]byte(largeRule))),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "origin robots.txt",
+			originRobotsTxt:    "User-agent: *\nDisallow: /origin",
+			expectedResponse:   "User-agent: *\nDisallow: /origin",
+			expectedSource:     "origin",
+			expectedStatus:     "200",
+			expectedLength:     "31",
+			expectedHash:       "7d85b101c00b4c9dc87fed0416e8ee9a7945e0b741ee9a1581c04004e1d5299c",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			var httpClient *http.Client
+			if test.customRule != "" {
+				ruleRepo.On("GetByUrl", mock.Anything).Return(
+					&model.Rule{ID: 1, Domain: "example.com", RobotsTxt: test.customRule}, nil)
+			} else {
+				ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+				cache.On("GetRobotsFile", mock.Anything).Return("", time.Duration(0), false)
+				cache.On("SaveRobotsFile", mock.Anything, mock.Anything).Once()
+				httpMock := httptest.NewRecorder()
+				httpMock.WriteString(test.originRobotsTxt)
+				httpClient = &http.Client{Transport: &mockRoundTripper{httpMock.Result()}}
+			}
+			cfg := testConfig()
+			cfg.HttpClientSettings.MaxRobotsSize = 1
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, httpClient)
+			r.GET("/effective-robots", robotsHandler.GetEffectiveRobots)
+			req, _ := http.NewRequest("GET", "/effective-robots?url=https://example.com/test", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			assert.Equal(tt, test.expectedSource, w.Header().Get("X-Robots-Source"))
+			assert.Equal(tt, test.expectedStatus, w.Header().Get("X-Robots-Status"))
+			assert.Equal(tt, test.expectedTruncated, w.Header().Get("X-Robots-Truncated"))
+			assert.Equal(tt, test.expectedLength, w.Header().Get("X-Robots-Length"))
+			assert.Equal(tt, test.expectedHash, w.Header().Get("X-Robots-Hash"))
+		})
+	}
+}
+
+func Test_GetEffectiveRobots_MissingUrl_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), nil, nil, nil, nil)
+	r.GET("/effective-robots", robotsHandler.GetEffectiveRobots)
+	req, _ := http.NewRequest("GET", "/effective-robots", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "{\"error\":\"'url' query parameter is required\"}", w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// errNoOriginRobotsTxt is returned if the origin responds without robots.txt content.
+var errNoOriginRobotsTxt = errors.New("origin has no robots.txt")
+
+// CreateCustomRuleFromOrigin godoc
+// @Summary Create a custom rule from the origin robots.txt
+// @Description Fetch the live robots.txt file of the url and save it as a custom rule. The fetched robots.txt url
+// @Description is saved in 'source_url' of the rule. The cache is not used and not changed.
+// @Description If asynchronous writes are enabled, the rule is queued and the tracking id is returned.
+// @Tags Custom Rule
+// @Produce json
+// @Param url query string true "URL of the site"
+// @Param note query string false "Human-readable note on why the rule exists. Max 255 characters"
+// @Success 200 {object} string "Custom rule created successfully. The id and the normalized domain are returned"
+// @Success 202 {object} string "Custom rule queued for creation. The tracking id and the normalized domain are returned"
+// @Header 200,202,500,502 {string} X-Robots-Status "Origin status code of robots.txt"
+// @Failure 400 {object} error "Bad request, missing 'url', url with credentials or too long note"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 502 {object} error "Origin responded without robots.txt, e.g. with 404 or an empty file"
+// @Failure 503 {object} error "Database is unavailable or origin is rate limiting robots.txt requests"
+// @Security ApiKeyAuth
+// @Router /custom-rule/from-origin [post]
+func (h *RobotsHandler) CreateCustomRuleFromOrigin(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+	note, ok := parseNote(c)
+	if !ok {
+		return
+	}
+	domain, err := h.getDomain(url)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse url. %s", err.Error())})
+		return
+	}
+
+	robotsTxt, sourceUrl, status, err := h.fetchLiveRobotsTxt(url)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(liveRobotsTxtErrorStatus(c, err),
+			gin.H{"error": fmt.Sprintf("failed to fetch robots.txt. %s", err.Error())})
+		return
+	}
+
+	rule := &model.Rule{
+		Domain:    domain,
+		RobotsTxt: robotsTxt,
+		Note:      note,
+		SourceUrl: &sourceUrl,
+	}
+	h.saveCustomRule(c, rule, "")
+}
+
+// fetchLiveRobotsTxt fetches the robots.txt file of the url from origin without the cache. The robots.txt url
+// and the origin status code are returned too. errNoOriginRobotsTxt is returned if the origin responds
+// without robots.txt content.
+func (h *RobotsHandler) fetchLiveRobotsTxt(url string) (string, string, string, error) {
+	baseUrl, err := util.GetBaseUrl(url)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to parse url. %s", err.Error())
+	}
+	robotsUrl := baseUrl + "/robots.txt"
+	resp, err := h.requestToRobotsTxt(url)
+	var status string
+	if resp != nil {
+		status = strconv.Itoa(resp.statusCode)
+	}
+	if errors.Is(err, errHtmlRobotsTxt) {
+		return "", robotsUrl, status, fmt.Errorf("%w. %s", errNoOriginRobotsTxt, err.Error())
+	}
+	if err != nil {
+		return "", robotsUrl, status, err
+	}
+	if !isSuccess(resp.statusCode) {
+		return "", robotsUrl, status, fmt.Errorf("%w. Status code %d", errNoOriginRobotsTxt, resp.statusCode)
+	}
+	if len(resp.body) == 0 {
+		return "", robotsUrl, status, fmt.Errorf("%w. The file is empty", errNoOriginRobotsTxt)
+	}
+
+	return string(resp.body), robotsUrl, status, nil
+}
+
+// liveRobotsTxtErrorStatus returns 502 if the origin has no robots.txt, otherwise the status of loadErrorStatus.
+func liveRobotsTxtErrorStatus(c *gin.Context, err error) int {
+	if errors.Is(err, errNoOriginRobotsTxt) {
+		return http.StatusBadGateway
+	}
+	return loadErrorStatus(c, err)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IliaW/robots-api/internal/model"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_CreateCustomRuleFromOrigin_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name                 string
+		target               string
+		mockHttpResponseCode int
+		mockHttpResponseBody string
+		expectedSave         bool
+		expectedResponse     string
+		expectedStatusCode   int
+	}{
+		{
+			name:                 "origin robots.txt is saved as custom rule",
+			target:               "/custom-rule/from-origin?url=https://www.example.com/page",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: *\nDisallow: /private",
+			expectedSave:         true,
+			expectedResponse:     "{\"domain\":\"www.example.com\",\"id\":1}",
+			expectedStatusCode:   http.StatusOK,
+		},
+		{
+			name:                 "origin without robots.txt",
+			target:               "/custom-rule/from-origin?url=https://example.com",
+			mockHttpResponseCode: http.StatusNotFound,
+			expectedResponse: "{\"error\":\"failed to fetch robots.txt. origin has no robots.txt. " +
+				"Status code 404\"}",
+			expectedStatusCode: http.StatusBadGateway,
+		},
+		{
+			name:                 "origin with empty robots.txt",
+			target:               "/custom-rule/from-origin?url=https://example.com",
+			mockHttpResponseCode: http.StatusOK,
+			expectedResponse: "{\"error\":\"failed to fetch robots.txt. origin has no robots.txt. " +
+				"The file is empty\"}",
+			expectedStatusCode: http.StatusBadGateway,
+		},
+		{
+			name:               "missing url",
+			target:             "/custom-rule/from-origin",
+			expectedResponse:   "{\"error\":\"'url' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			if test.expectedSave {
+				ruleRepo.On("Save", mock.MatchedBy(func(rule *model.Rule) bool {
+					return rule.Domain == "www.example.com" && rule.RobotsTxt == test.mockHttpResponseBody &&
+						rule.SourceUrl != nil && *rule.SourceUrl == "https://www.example.com/robots.txt"
+				})).Return(int64(1), nil)
+			}
+			httpMock := httptest.NewRecorder()
+			httpMock.WriteString(test.mockHttpResponseBody)
+			httpMock.Code = test.mockHttpResponseCode
+			httpClient := &http.Client{Transport: &mockRoundTripper{httpMock.Result()}}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), nil, ruleRepo, nil, httpClient)
+			r.POST("/custom-rule/from-origin", robotsHandler.CreateCustomRuleFromOrigin)
+			req, _ := http.NewRequest("POST", test.target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
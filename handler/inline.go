@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EvaluateAllowedScrape godoc
+// @Summary Check if scraping is allowed by the robots.txt content from the request body
+// @Description Evaluate the url and user agent against the robots.txt content from the body. Custom rules, cache
+// @Description and origin are not used and nothing is saved. An empty body allows everything.
+// @Description The errors are plain text, or JSON if the Accept header prefers application/json
+// @Tags Scraping
+// @Accept plain
+// @Produce plain,json
+// @Param url query string true "URL to check"
+// @Param user_agent query string false "User agent to check. Required if 'robots.default_user_agent' is not configured"
+// @Param explain query bool false "Return the rule that decided the result as JSON"
+// @Param file body string false "robots.txt content"
+// @Success 200 {string} true "true or false depending on whether scraping is allowed. JSON explanation with confidence if 'explain' is true"
+// @Failure 400 {string} string "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /scrape-allowed [post]
+func (h *RobotsHandler) EvaluateAllowedScrape(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		scrapeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if url == "" {
+		scrapeError(c, http.StatusBadRequest, "'url' query parameter is required")
+		return
+	}
+	userAgent, err := h.requestUserAgent(c)
+	if err != nil {
+		scrapeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		scrapeError(c, http.StatusInternalServerError, fmt.Sprintf("unable to read robots.txt. %s", err.Error()))
+		return
+	}
+	robotsTxt := string(body)
+
+	if c.Query("explain") == "true" {
+		c.JSON(http.StatusOK, h.explain(robotsTxt, userAgent, url, false))
+		return
+	}
+
+	if ok := h.matcher.AgentAllowed(robotsTxt, userAgent, url); ok {
+		c.String(http.StatusOK, "true")
+		return
+	}
+
+	c.String(http.StatusOK, "false")
+}
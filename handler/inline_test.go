@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EvaluateAllowedScrape_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		target             string
+		robotsTxt          string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "allowed by inline content",
+			target:             "/scrape-allowed?url=https://example.com/public&user_agent=bot",
+			robotsTxt:          "User-agent: *\nDisallow: /private\n",
+			expectedResponse:   "true",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "disallowed by inline content",
+			target:             "/scrape-allowed?url=https://example.com/private/page&user_agent=bot",
+			robotsTxt:          "User-agent: *\nDisallow: /private\n",
+			expectedResponse:   "false",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "empty content allows everything",
+			target:             "/scrape-allowed?url=https://example.com/private&user_agent=bot",
+			robotsTxt:          "",
+			expectedResponse:   "true",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:      "explanation of inline content",
+			target:    "/scrape-allowed?url=https://example.com/private&user_agent=bot&explain=true",
+			robotsTxt: "User-agent: *\nDisallow: /private\n",
+			expectedResponse: "{\"allowed\":false,\"rule\":{\"directive\":\"disallow\",\"pattern\":\"/private\"," +
+				"\"line\":2},\"confidence\":\"high\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missing url",
+			target:             "/scrape-allowed?user_agent=bot",
+			robotsTxt:          "User-agent: *\nDisallow: /\n",
+			expectedResponse:   "error: 'url' query parameter is required",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "missing user agent",
+			target:             "/scrape-allowed?url=https://example.com/",
+			robotsTxt:          "User-agent: *\nDisallow: /\n",
+			expectedResponse:   "error: 'user_agent' query parameter is required",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			// no cache, storage and http client, so the content is never fetched or saved
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), nil, nil, nil, nil)
+			r.POST("/scrape-allowed", robotsHandler.EvaluateAllowedScrape)
+			req, _ := http.NewRequest("POST", test.target, strings.NewReader(test.robotsTxt))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
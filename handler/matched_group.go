@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMatchedGroup godoc
+// @Summary Get the robots.txt groups that apply to the user agent
+// @Description Resolve the rules the same way as the scrape check and return the user-agent values of the groups
+// @Description selected for the user agent, and whether the '*' group is used as a fallback
+// @Tags Scraping
+// @Produce json
+// @Param url query string true "URL to check"
+// @Param user_agent query string false "User agent to match. Required if 'robots.default_user_agent' is not configured"
+// @Success 200 {object} util.MatchedGroup "User agents of the matched groups"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /matched-group [get]
+func (h *RobotsHandler) GetMatchedGroup(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+	userAgent, err := h.requestUserAgent(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	robotsTxt, status, err := h.resolveAgentRobotsTxt(url, userAgent)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, util.MatchGroup(robotsTxt, h.matcher.UserAgent(userAgent)))
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetMatchedGroup_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		query              string
+		allowedUserAgents  []string
+		defaultUserAgent   string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "group of the user agent",
+			query:              "url=https://example.com/test&user_agent=googlebot",
+			expectedResponse:   "{\"user_agents\":[\"Googlebot/2.1\"],\"wildcard\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "wildcard fallback",
+			query:              "url=https://example.com/test&user_agent=somebot",
+			expectedResponse:   "{\"user_agents\":[\"*\"],\"wildcard\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missed user agent in query",
+			query:              "url=https://example.com/test",
+			expectedResponse:   "{\"error\":\"'user_agent' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "default user agent is used without 'user_agent'",
+			query:              "url=https://example.com/test",
+			defaultUserAgent:   "googlebot",
+			expectedResponse:   "{\"user_agents\":[\"Googlebot/2.1\"],\"wildcard\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "user agent is not in the allowlist",
+			query:              "url=https://example.com/test&user_agent=somebot",
+			allowedUserAgents:  []string{"googlebot"},
+			expectedResponse:   "{\"error\":\"user agent 'somebot' is not in the allowlist\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Maybe().
+				Return("User-agent: Googlebot/2.1\nDisallow: /private\n\nUser-agent: *\nDisallow: /", time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+			cfg := testConfig()
+			cfg.RobotsSettings.AllowedUserAgents = test.allowedUserAgents
+			cfg.RobotsSettings.DefaultUserAgent = test.defaultUserAgent
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, nil)
+			r.GET("/matched-group", robotsHandler.GetMatchedGroup)
+			req, _ := http.NewRequest("GET", "/matched-group?"+test.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRobotsMeta godoc
+// @Summary Get the summary of the robots.txt rules for the url
+// @Description Resolve the rules the same way as the scrape check (custom rule, cache or origin) and report
+// @Description their source, size, number of user-agent groups and sitemaps. The number of sitemaps is limited
+// @Description by 'robots.max_sitemaps'. The Visit-time (preferred crawl window) is of the group that applies
+// @Description to 'user_agent', or of the '*' group if 'user_agent' is not sent
+// @Tags Scraping
+// @Produce json
+// @Param url query string true "URL to check"
+// @Param user_agent query string false "User agent whose group the Visit-time is taken from. The '*' group by default"
+// @Success 200 {object} model.RobotsMeta "Summary of the robots.txt rules"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, missing 'url' or url with credentials"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /robots-meta [get]
+func (h *RobotsHandler) GetRobotsMeta(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+
+	robotsTxt, status, err := h.resolveRobotsTxt(url)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+
+	userAgent := c.Query("user_agent")
+	if userAgent == "" {
+		userAgent = "*"
+	}
+	sitemaps, truncated := h.sitemaps(robotsTxt)
+	c.JSON(http.StatusOK, &model.RobotsMeta{
+		Url:               url,
+		Source:            status,
+		Size:              len(robotsTxt),
+		UserAgentGroups:   util.CountUserAgentGroups(robotsTxt),
+		UserAgents:        util.DeclaredUserAgents(robotsTxt),
+		Sitemaps:          sitemaps,
+		SitemapsTruncated: truncated,
+		VisitTime:         util.MatchVisitTime(robotsTxt, h.matcher.UserAgent(userAgent)),
+	})
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetRobotsMeta_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name                  string
+		url                   string
+		mockStorageCustomRule func() (*model.Rule, error)
+		expectedResponse      string
+		expectedStatusCode    int
+	}{
+		{
+			name: "robots.txt from cache",
+			url:  "https://example.com/test",
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, persistence.ErrNotFound
+			},
+			expectedResponse: "{\"url\":\"https://example.com/test\",\"source\":\"cache\",\"size\":124," +
+				"\"user_agent_groups\":2,\"user_agents\":[\"googlebot\",\"bingbot\",\"*\"]," +
+				"\"sitemaps\":[\"https://example.com/sitemap.xml\"],\"sitemaps_truncated\":false,\"visit_time\":null}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "custom rule",
+			url:  "https://example.com/test",
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nAllow: /"}, nil
+			},
+			expectedResponse: "{\"url\":\"https://example.com/test\",\"source\":\"custom\",\"size\":22," +
+				"\"user_agent_groups\":1,\"user_agents\":[\"*\"],\"sitemaps\":[],\"sitemaps_truncated\":false," +
+				"\"visit_time\":null}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missed url in query",
+			url:                "",
+			expectedResponse:   "{\"error\":\"'url' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Maybe().Return("User-agent: googlebot\nUser-agent: bingbot\n"+
+				"Disallow: /private\nUser-agent: *\nAllow: /\nSitemap: https://example.com/sitemap.xml",
+				time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			if test.mockStorageCustomRule != nil {
+				ruleRepo.On("GetByUrl", mock.Anything).Return(test.mockStorageCustomRule())
+			}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, nil)
+			r.GET("/robots-meta", robotsHandler.GetRobotsMeta)
+			req, _ := http.NewRequest("GET", "/robots-meta?url="+test.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_GetRobotsMeta_VisitTime_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	robotsTxt := "User-agent: mybot\nVisit-time: 0100-0500\nDisallow: /private\n\n" +
+		"User-agent: *\nVisit-time: 2200-0600\nDisallow: /"
+	testSet := []struct {
+		name              string
+		query             string
+		expectedVisitTime string
+	}{
+		{name: "group of the user agent", query: "&user_agent=mybot", expectedVisitTime: `{"from":"0100","to":"0500"}`},
+		{name: "wildcard group by default", query: "", expectedVisitTime: `{"from":"2200","to":"0600"}`},
+		{name: "wildcard group of other user agent", query: "&user_agent=otherbot",
+			expectedVisitTime: `{"from":"2200","to":"0600"}`},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Return(robotsTxt, time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, nil)
+			r.GET("/robots-meta", robotsHandler.GetRobotsMeta)
+			req, _ := http.NewRequest("GET", "/robots-meta?url=https://example.com/test"+test.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, http.StatusOK, w.Code)
+			assert.Contains(tt, w.Body.String(), `"visit_time":`+test.expectedVisitTime+"}")
+		})
+	}
+}
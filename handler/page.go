@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAllowedPage godoc
+// @Summary Check if the page is allowed to be crawled and indexed
+// @Description Combine the robots.txt decision with the X-Robots-Tag header of the page, fetched with a HEAD request.
+// @Description The page is not requested if robots.txt disallows crawling it.
+// @Tags Scraping
+// @Produce json
+// @Param url query string true "URL of the page"
+// @Param user_agent query string false "User agent to check. Required if 'robots.default_user_agent' is not configured"
+// @Success 200 {object} model.PagePermissions "Crawl and index permissions"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Failure 502 {object} error "Failed to fetch the page"
+// @Security ApiKeyAuth
+// @Router /page-allowed [get]
+func (h *RobotsHandler) GetAllowedPage(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+	userAgent, err := h.requestUserAgent(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	robotsTxt, status, err := h.resolveAgentRobotsTxt(url, userAgent)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+
+	permissions := &model.PagePermissions{
+		CrawlAllowed: h.matcher.AgentAllowed(robotsTxt, userAgent, url),
+	}
+	if !permissions.CrawlAllowed {
+		c.JSON(http.StatusOK, permissions)
+		return
+	}
+
+	robotsTags, err := h.requestRobotsTags(url)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("failed to fetch the page. %s", err.Error())})
+		return
+	}
+	permissions.IndexAllowed = util.IsIndexAllowed(robotsTags, userAgent)
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// requestRobotsTags returns the X-Robots-Tag header values of the page. The page is requested with GET if the
+// origin doesn't support HEAD. The page is fetched by the same client as robots.txt, so the denied networks and
+// timeouts apply, and at most 'http_client.max_robots_size' of the body is read.
+func (h *RobotsHandler) requestRobotsTags(url string) ([]string, error) {
+	resp, err := h.requestPage(http.MethodHead, url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		slog.Debug("page doesn't support HEAD request. Use GET.", slog.String("url", url))
+		h.closePage(resp)
+		resp, err = h.requestPage(http.MethodGet, url)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer h.closePage(resp)
+
+	return resp.Header.Values("X-Robots-Tag"), nil
+}
+
+func (h *RobotsHandler) requestPage(method, url string) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		slog.Error(fmt.Sprintf("error making http %s request to %s", strings.ToLower(method), url),
+			slog.String("err", err.Error()))
+		return nil, err
+	}
+	return resp, nil
+}
+
+// closePage reads the rest of the page body up to the size limit, so the connection can be reused, and closes it.
+func (h *RobotsHandler) closePage(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, h.maxRobotsSize())
+	if err := resp.Body.Close(); err != nil {
+		slog.Error("error closing response body", slog.String("err", err.Error()))
+	}
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/httpclient"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetAllowedPage_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		url                string
+		robotsTags         []string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "page without X-Robots-Tag header",
+			url:                "https://example.com/test",
+			robotsTags:         nil,
+			expectedResponse:   "{\"crawl_allowed\":true,\"index_allowed\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "page with noindex X-Robots-Tag header",
+			url:                "https://example.com/test",
+			robotsTags:         []string{"noarchive", "noindex, nofollow"},
+			expectedResponse:   "{\"crawl_allowed\":true,\"index_allowed\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "page with noindex X-Robots-Tag header for another user agent",
+			url:                "https://example.com/test",
+			robotsTags:         []string{"otherbot: noindex"},
+			expectedResponse:   "{\"crawl_allowed\":true,\"index_allowed\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "page disallowed by robots.txt",
+			url:                "https://example.com/private",
+			robotsTags:         nil,
+			expectedResponse:   "{\"crawl_allowed\":false,\"index_allowed\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missed url in query",
+			url:                "",
+			expectedResponse:   "{\"error\":\"'url' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Maybe().
+				Return("User-agent: *\nDisallow: /private", time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+			pageResponse := httptest.NewRecorder()
+			for _, tag := range test.robotsTags {
+				pageResponse.Header().Add("X-Robots-Tag", tag)
+			}
+			httpClient := &http.Client{Transport: &mockRoundTripper{pageResponse.Result()}}
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, httpClient)
+			r.GET("/page-allowed", robotsHandler.GetAllowedPage)
+			req, _ := http.NewRequest("GET", "/page-allowed?url="+test.url+"&user_agent=bot", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_GetAllowedPage_UserAgent_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name                 string
+		query                string
+		defaultUserAgent     string
+		emptyAgentAsWildcard bool
+		expectedResponse     string
+		expectedStatusCode   int
+	}{
+		{
+			name:               "default user agent is used without 'user_agent'",
+			query:              "",
+			defaultUserAgent:   "bot",
+			expectedResponse:   "{\"crawl_allowed\":false,\"index_allowed\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "empty user agent is checked as the wildcard",
+			query:                "&user_agent=",
+			emptyAgentAsWildcard: true,
+			expectedResponse:     "{\"crawl_allowed\":false,\"index_allowed\":false}",
+			expectedStatusCode:   http.StatusOK,
+		},
+		{
+			name:               "missed user agent without the default",
+			query:              "",
+			expectedResponse:   "{\"error\":\"'user_agent' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Maybe().
+				Return("User-agent: *\nDisallow: /private", time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+			cfg := testConfig()
+			cfg.RobotsSettings.DefaultUserAgent = test.defaultUserAgent
+			cfg.RobotsSettings.EmptyAgentAsWildcard = test.emptyAgentAsWildcard
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, nil)
+			r.GET("/page-allowed", robotsHandler.GetAllowedPage)
+			req, _ := http.NewRequest("GET", "/page-allowed?url=https://example.com/private"+test.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_GetAllowedPage_DeniedNetwork_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	requested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer srv.Close()
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", mock.Anything).Return("User-agent: *\nAllow: /", time.Hour, true)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+	httpClient := httpclient.NewHttpClient(&config.HttpClientConfig{
+		RequestTimeout: 5 * time.Second,
+		DeniedNetworks: []string{"127.0.0.0/8", "::1/128"},
+	})
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, httpClient)
+	r.GET("/page-allowed", robotsHandler.GetAllowedPage)
+	req, _ := http.NewRequest("GET", "/page-allowed?url="+srv.URL+"/test&user_agent=bot", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "address is denied")
+	assert.False(t, requested)
+}
+
+// countingReader is an endless page body that counts the read bytes.
+type countingReader struct {
+	read int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func Test_GetAllowedPage_HeadNotAllowed_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", mock.Anything).Return("User-agent: *\nAllow: /", time.Hour, true)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+	body := &countingReader{}
+	var methods []string
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		methods = append(methods, req.Method)
+		if req.Method == http.MethodHead {
+			return &http.Response{StatusCode: http.StatusMethodNotAllowed, Header: http.Header{},
+				Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Robots-Tag": {"noindex"}},
+			Body: io.NopCloser(body)}, nil
+	})}
+	cfg := testConfig()
+	cfg.HttpClientSettings.MaxRobotsSize = 1
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, httpClient)
+	r.GET("/page-allowed", robotsHandler.GetAllowedPage)
+	req, _ := http.NewRequest("GET", "/page-allowed?url=https://example.com/test&user_agent=bot", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "{\"crawl_allowed\":true,\"index_allowed\":false}", w.Body.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{http.MethodHead, http.MethodGet}, methods)
+	assert.LessOrEqual(t, body.read, int64(1024))
+}
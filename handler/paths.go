@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	u "net/url"
+	"strings"
+
+	"github.com/IliaW/robots-api/internal/metrics"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxPaths = 1000
+
+// GetAllowedPaths godoc
+// @Summary Check if scraping is allowed for many paths of one domain
+// @Description Resolve the robots.txt rules of the domain once (custom rule, cache or origin) and check every path
+// @Description for the user agent. The number of paths is limited by 'robots.max_paths'
+// @Tags Scraping
+// @Accept json
+// @Produce json
+// @Param request body model.PathsCheckRequest true "Domain, user agent and paths to check"
+// @Success 200 {object} map[string]bool "Decision of every path, e.g. {\"/a\":true,\"/b\":false}"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, invalid domain or path, no or too many paths, missing 'user_agent' or the user agent is not in the allowlist"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /scrape-allowed/paths [post]
+func (h *RobotsHandler) GetAllowedPaths(c *gin.Context) {
+	var request model.PathsCheckRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request body. %s", err.Error())})
+		return
+	}
+	baseUrl, err := domainUrl(request.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userAgent, err := h.bodyUserAgent(request.UserAgent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Paths) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'paths' must not be empty"})
+		return
+	}
+	if maxPaths := h.maxPaths(); len(request.Paths) > maxPaths {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many paths. Max is %d", maxPaths)})
+		return
+	}
+	for _, path := range request.Paths {
+		if !strings.HasPrefix(path, "/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("path '%s' must start with '/'", path)})
+			return
+		}
+	}
+
+	// the paths check is a bulk request, so the custom rule is read by GetByUrlBulk
+	resolved, err := h.resolveAgent(h.ruleRepo.GetByUrlBulk, baseUrl+"/", userAgent)
+	robotsTxt, status := resolved.robotsTxt, resolved.status
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+	source := decisionSource(status)
+	metrics.ScrapeDecisions.WithLabelValues(source).Add(float64(len(request.Paths)))
+	slog.Debug("scrape check of paths decided.", slog.String("domain", request.Domain),
+		slog.Int("paths", len(request.Paths)), slog.String("source", source))
+
+	decisions := make(map[string]bool, len(request.Paths))
+	for _, path := range request.Paths {
+		decisions[path] = h.matcher.AgentAllowed(robotsTxt, userAgent, util.NormalizeUrl(baseUrl+path))
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+// domainUrl returns the https url of the domain. The domain must be a bare host, optionally with a port,
+// without scheme, path or credentials.
+func domainUrl(domain string) (string, error) {
+	if domain == "" {
+		return "", fmt.Errorf("'domain' is required")
+	}
+	parsedUrl, err := u.Parse("https://" + domain)
+	if err != nil || parsedUrl.Host != domain || parsedUrl.Hostname() == "" {
+		return "", fmt.Errorf("invalid domain '%s'. The domain should be a host without scheme and path", domain)
+	}
+	if _, err = util.NormalizeDomain(parsedUrl.Hostname()); err != nil {
+		return "", fmt.Errorf("invalid domain '%s'. %s", domain, err.Error())
+	}
+
+	return "https://" + domain, nil
+}
+
+// bodyUserAgent returns the 'user_agent' of the request body, or 'robots.default_user_agent' if it is empty.
+// The sent user agent must be in the allowlist.
+func (h *RobotsHandler) bodyUserAgent(userAgent string) (string, error) {
+	if userAgent == "" {
+		userAgent = h.cfg.RobotsSettings.DefaultUserAgent
+	} else if !h.isUserAgentAllowed(userAgent) {
+		return "", fmt.Errorf("user agent '%s' is not in the allowlist", userAgent)
+	}
+	if userAgent == "" {
+		return "", errors.New("'user_agent' is required")
+	}
+	return userAgent, nil
+}
+
+func (h *RobotsHandler) maxPaths() int {
+	if limit := h.cfg.RobotsSettings.MaxPaths; limit > 0 {
+		return limit
+	}
+	return defaultMaxPaths
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetAllowedPaths_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", "https://example.com/").
+		Return("User-agent: *\nDisallow: /private\nAllow: /private/public\n\nUser-agent: bot\nDisallow: /b\n",
+			time.Hour, true).
+		Once()
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrlBulk", "https://example.com/").Return(nil, persistence.ErrNotFound).Once()
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, nil)
+	r.POST("/scrape-allowed/paths", robotsHandler.GetAllowedPaths)
+	req, _ := http.NewRequest("POST", "/scrape-allowed/paths", strings.NewReader(
+		`{"domain":"example.com","user_agent":"bot","paths":["/a","/b","/b/c?q=1","/private"]}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, `{"/a":true,"/b":false,"/b/c?q=1":false,"/private":true}`, w.Body.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, robotsStatusCache, w.Header().Get(robotsStatusHeader))
+}
+
+func Test_GetAllowedPaths_BadRequest_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name             string
+		body             string
+		expectedResponse string
+	}{
+		{
+			name:             "missing domain",
+			body:             `{"user_agent":"bot","paths":["/a"]}`,
+			expectedResponse: `{"error":"'domain' is required"}`,
+		},
+		{
+			name: "domain with scheme",
+			body: `{"domain":"https://example.com","user_agent":"bot","paths":["/a"]}`,
+			expectedResponse: `{"error":"invalid domain 'https://example.com'. ` +
+				`The domain should be a host without scheme and path"}`,
+		},
+		{
+			name: "domain with path",
+			body: `{"domain":"example.com/a","user_agent":"bot","paths":["/a"]}`,
+			expectedResponse: `{"error":"invalid domain 'example.com/a'. ` +
+				`The domain should be a host without scheme and path"}`,
+		},
+		{
+			name: "domain with credentials",
+			body: `{"domain":"user:pass@example.com","user_agent":"bot","paths":["/a"]}`,
+			expectedResponse: `{"error":"invalid domain 'user:pass@example.com'. ` +
+				`The domain should be a host without scheme and path"}`,
+		},
+		{
+			name:             "missing user agent",
+			body:             `{"domain":"example.com","paths":["/a"]}`,
+			expectedResponse: `{"error":"'user_agent' is required"}`,
+		},
+		{
+			name:             "no paths",
+			body:             `{"domain":"example.com","user_agent":"bot","paths":[]}`,
+			expectedResponse: `{"error":"'paths' must not be empty"}`,
+		},
+		{
+			name:             "too many paths",
+			body:             `{"domain":"example.com","user_agent":"bot","paths":["/a","/b","/c"]}`,
+			expectedResponse: `{"error":"too many paths. Max is 2"}`,
+		},
+		{
+			name:             "relative path",
+			body:             `{"domain":"example.com","user_agent":"bot","paths":["a"]}`,
+			expectedResponse: `{"error":"path 'a' must start with '/'"}`,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := testConfig()
+			cfg.RobotsSettings.MaxPaths = 2
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, nil, nil, nil, nil)
+			r.POST("/scrape-allowed/paths", robotsHandler.GetAllowedPaths)
+			req, _ := http.NewRequest("POST", "/scrape-allowed/paths", strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -1,78 +1,219 @@
 package handler
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/IliaW/robots-api/config"
 	cacheClient "github.com/IliaW/robots-api/internal/cache"
+	"github.com/IliaW/robots-api/internal/httpclient"
+	"github.com/IliaW/robots-api/internal/metrics"
 	"github.com/IliaW/robots-api/internal/model"
 	"github.com/IliaW/robots-api/internal/persistence"
 	"github.com/IliaW/robots-api/util"
 	"github.com/gin-gonic/gin"
-	"github.com/jimsmart/grobotstxt"
+	"github.com/gin-gonic/gin/binding"
+	"golang.org/x/sync/singleflight"
 )
 
+// errHtmlRobotsTxt is returned when the origin serves an HTML page instead of robots.txt.
+var errHtmlRobotsTxt = errors.New("robots.txt is served as html")
+
+// rateLimitedError is returned when the origin responds with 429 to the robots.txt request.
+type rateLimitedError struct {
+	retryAfter string
+}
+
+func (e *rateLimitedError) Error() string {
+	return "origin is rate limiting robots.txt requests"
+}
+
+// maxNoteLength is the size of the custom_rule.note column in characters.
+const maxNoteLength = 255
+
+// robotsStatusHeader reports the origin status code of the robots.txt fetch,
+// or where the rules came from when robots.txt was not fetched live.
+const (
+	robotsStatusHeader   = "X-Robots-Status"
+	robotsStatusCache    = "cache"
+	robotsStatusCustom   = "custom"
+	robotsStatusOverride = "override"
+)
+
+// robotsWarningHeader marks a degraded decision, e.g. 'fetch-failed' if robots.txt couldn't be fetched and
+// the url is allowed by 'robots.allow_on_fetch_error'.
+const (
+	robotsWarningHeader      = "X-Robots-Warning"
+	robotsWarningFetchFailed = "fetch-failed"
+)
+
+// robotsOverrideHeader is the base64-encoded robots.txt content used instead of the custom rule, cache and origin.
+const robotsOverrideHeader = "X-Robots-Override"
+
 type RobotsHandler struct {
+	cfg        *config.Config
 	cache      cacheClient.CachedClient
 	ruleRepo   persistence.RuleStorage
+	ruleQueue  *persistence.RuleWriteQueue
+	ruleCache  *persistence.RuleCache
 	httpClient *http.Client
+	getDomain  util.DomainFunc
+	matcher    util.Matcher
+	fetchGroup singleflight.Group
+	resolution []string
 }
 
-func NewRobotsHandler(cache cacheClient.CachedClient, ruleRepo persistence.RuleStorage, httpClient *http.Client) *RobotsHandler {
+func NewRobotsHandler(cfg *config.Config, cache cacheClient.CachedClient, ruleRepo persistence.RuleStorage,
+	ruleQueue *persistence.RuleWriteQueue, httpClient *http.Client) *RobotsHandler {
+	var ruleCache *persistence.RuleCache
+	if cfg.PersistenceSettings != nil && cfg.PersistenceSettings.RuleCacheSize > 0 {
+		ruleCache = persistence.NewRuleCache(cfg.PersistenceSettings.RuleCacheSize, cfg.PersistenceSettings.RuleCacheTtl)
+	}
+	// the order is validated on start, so an invalid order is only possible in tests
+	resolution, err := config.ParseResolutionOrder(cfg.RobotsSettings.ResolutionOrder)
+	if err != nil {
+		resolution, _ = config.ParseResolutionOrder(config.DefaultResolutionOrder)
+	}
 	return &RobotsHandler{
+		cfg:        cfg,
 		cache:      cache,
 		ruleRepo:   ruleRepo,
+		ruleQueue:  ruleQueue,
+		ruleCache:  ruleCache,
 		httpClient: httpClient,
+		getDomain:  util.NewDomainFunc(cfg.RobotsSettings.RegistrableDomainKey, cfg.RobotsSettings.StripWww),
+		matcher:    util.NewMatcher(cfg.RobotsSettings.StrictRfc),
+		resolution: resolution,
 	}
 }
 
 // GetAllowedScrape godoc
 // @Summary Check if scraping is allowed for a specific user agent and URL
-// @Description Check if the given user agent is allowed to scrape the specified URL based on the robots.txt rules
+// @Description Check if the given user agent is allowed to scrape the specified URL based on the robots.txt rules.
+// @Description The errors are plain text, or JSON if the Accept header prefers application/json
 // @Tags Scraping
-// @Produce plain
+// @Produce plain,json
 // @Param url query string true "URL to check"
-// @Param user_agent query string true "User agent to check"
-// @Success 200 {string} true "true or false depending on whether scraping is allowed"
-// @Failure 400 {string} string "Bad request, missing 'url' or 'user_agent'"
+// @Param user_agent query string false "User agent to check. Required if 'robots.default_user_agent' is not configured"
+// @Param explain query bool false "Return the rule that decided the result as JSON"
+// @Param If-None-Match header string false "ETag of the previous response"
+// @Param X-Robots-Override header string false "Base64-encoded robots.txt content to check instead of the custom rule, cache and origin. Requires X-API-Key"
+// @Success 200 {string} true "true or false depending on whether scraping is allowed. JSON explanation with confidence if 'explain' is true"
+// @Success 304 "The decision is unchanged since the response with the ETag from If-None-Match"
+// @Header 200,304 {string} ETag "Hash of the robots.txt content, user agent and url the decision is based on"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache', 'custom' or 'override'"
+// @Header 200 {string} X-Robots-Warning "'fetch-failed' if robots.txt couldn't be fetched and the url is allowed by default"
+// @Header 200,304 {integer} X-Robots-Length "Length of the robots.txt the decision is based on in bytes"
+// @Header 200,304 {string} X-Robots-Hash "Hex sha256 hash of the robots.txt the decision is based on"
+// @Header 200,304 {string} Cache-Control "max-age of the decision: the remaining TTL of the cached robots.txt or 'robots.custom_rule_max_age' for the custom rule"
+// @Failure 400 {string} string "Bad request, missing 'url' or 'user_agent', url with credentials, the user agent is not in the allowlist or invalid X-Robots-Override"
+// @Failure 422 {string} string "The custom rule is unparseable and 'robots.strict_custom_rules' is enabled"
 // @Failure 500 {string} string "Internal server error"
+// @Failure 503 {string} string "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
 // @Security ApiKeyAuth
 // @Router /scrape-allowed [get]
 func (h *RobotsHandler) GetAllowedScrape(c *gin.Context) {
-	url := c.Query("url")
+	url, err := h.requestUrl(c)
+	if err != nil {
+		scrapeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if url == "" {
-		c.String(http.StatusBadRequest, "error: 'url' query parameter is required")
+		scrapeError(c, http.StatusBadRequest, "'url' query parameter is required")
 		return
 	}
-	userAgent := c.Query("user_agent")
-	if userAgent == "" {
-		c.String(http.StatusBadRequest, "error: 'user_agent' query parameter is required")
+	userAgent, err := h.requestUserAgent(c)
+	if err != nil {
+		scrapeError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	var robotsTxt string
-	// check the custom rule for the given url in database
-	rule, err := h.ruleRepo.GetByUrl(url)
-	if err == nil && rule != nil && rule.RobotsTxt != "" {
-		robotsTxt = rule.RobotsTxt
-	} else {
-		// upload the robots.txt file if custom rule is not found in database
-		robotsTxt, err = h.getRobotsTxt(url)
+	resolved := &resolvedRobotsTxt{}
+	if override := c.GetHeader(robotsOverrideHeader); override != "" {
+		resolved.robotsTxt, err = h.decodeOverride(override)
 		if err != nil {
-			c.String(http.StatusInternalServerError, fmt.Sprintf("error: failed to load robots.txt. %s", err.Error()))
+			scrapeError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		resolved.status = robotsStatusOverride
+	} else {
+		resolved, err = h.resolveAgent(h.ruleRepo.GetByUrl, url, userAgent)
+	}
+	robotsTxt, status := resolved.robotsTxt, resolved.status
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	explain := c.Query("explain") == "true"
+	if err != nil && h.allowOnFetchError(err) {
+		slog.Warn("failed to load robots.txt. Allow by default.", slog.String("url", url),
+			slog.String("err", err.Error()))
+		metrics.ScrapeDecisions.WithLabelValues(metrics.SourceFetchFailed).Inc()
+		h.logDecision(url, userAgent, metrics.SourceFetchFailed, true)
+		c.Header(robotsWarningHeader, robotsWarningFetchFailed)
+		if explain {
+			c.JSON(http.StatusOK, &util.Explanation{Allowed: true, Confidence: util.ConfidenceLow,
+				Note: "robots.txt couldn't be fetched. The url is allowed by default"})
 			return
 		}
+		c.String(http.StatusOK, strconv.FormatBool(true))
+		return
+	}
+	if err != nil {
+		scrapeError(c, loadErrorStatus(c, err), fmt.Sprintf("failed to load robots.txt. %s", err.Error()))
+		return
+	}
+	if status == robotsStatusCustom && h.cfg.RobotsSettings.StrictCustomRules && util.IsUnparseable(robotsTxt) {
+		slog.Warn("custom rule is unparseable. Reject the scrape check.", slog.String("url", url))
+		scrapeError(c, http.StatusUnprocessableEntity,
+			"custom rule of the domain is unparseable. No line is a robots.txt directive")
+		return
+	}
+	source := decisionSource(status)
+	metrics.ScrapeDecisions.WithLabelValues(source).Inc()
+	setContentHeaders(c, robotsTxt)
+	h.setMaxAge(c, resolved)
+
+	etag := decisionETag(robotsTxt, userAgent, url, explain)
+	c.Header("ETag", etag)
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
 	}
 
-	if ok := grobotstxt.AgentAllowed(robotsTxt, userAgent, url); ok {
-		c.String(http.StatusOK, "true")
+	if explain {
+		explanation := h.explain(robotsTxt, userAgent, url, resolved.truncated)
+		h.logDecision(url, userAgent, source, explanation.Allowed)
+		c.JSON(http.StatusOK, explanation)
 		return
 	}
 
-	c.String(http.StatusOK, "false")
+	allowed := h.matcher.AgentAllowed(robotsTxt, userAgent, url)
+	h.logDecision(url, userAgent, source, allowed)
+	c.String(http.StatusOK, strconv.FormatBool(allowed))
+}
+
+// logDecision logs the scrape decision with the normalized domain, user agent, source and result as separate
+// attributes, e.g. for decision dashboards. It is a debug message, so it is logged only with the debug log level.
+func (h *RobotsHandler) logDecision(url, userAgent, source string, allowed bool) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	domain, _ := h.getDomain(url)
+	slog.Debug("scrape check decided.", slog.String("url", url), slog.String("domain", domain),
+		slog.String("user_agent", userAgent), slog.String("source", source), slog.Bool("allowed", allowed))
 }
 
 // GetCustomRule godoc
@@ -82,8 +223,12 @@ func (h *RobotsHandler) GetAllowedScrape(c *gin.Context) {
 // @Produce json
 // @Param id query string false "Custom rule ID"
 // @Param url query string false "Custom rule URL"
-// @Success 200 {object} model.Rule "Custom rule object"
-// @Failure 400 {object} error "Bad request. Either 'id' or 'url' must be provided"
+// @Param on_missing query string false "Status code if the rule doesn't exist: 404 (default) or 204" Enums(404, 204)
+// @Param include_freshness query bool false "Add 'age_seconds' and 'last_updated_seconds' of the rule"
+// @Success 200 {object} model.Rule "Custom rule object. The field names are camelCase if 'response.json_case' is camel"
+// @Success 204 "Rule not found and 'on_missing' is 204"
+// @Failure 400 {object} error "Bad request. Either 'id' or 'url' must be provided, not both"
+// @Failure 404 {object} error "Rule not found"
 // @Failure 500 {object} error "Internal server error"
 // @Security ApiKeyAuth
 // @Router /custom-rule [get]
@@ -94,39 +239,57 @@ func (h *RobotsHandler) GetCustomRule(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "'id' or 'url' query parameter is required"})
 		return
 	}
+	if id != "" && url != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provide either 'id' or 'url', not both"})
+		return
+	}
+	onMissing := c.DefaultQuery("on_missing", strconv.Itoa(http.StatusNotFound))
+	if onMissing != strconv.Itoa(http.StatusNotFound) && onMissing != strconv.Itoa(http.StatusNoContent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'on_missing' query parameter must be 404 or 204"})
+		return
+	}
 
+	var rule *model.Rule
+	var err error
 	if id != "" {
-		rule, err := h.ruleRepo.GetById(id)
-		if err != nil {
-			c.JSON(http.StatusNotFound,
-				gin.H{"error": fmt.Sprintf("failed to get rule by id. %s", err.Error())})
+		rule, err = h.ruleRepo.GetById(id)
+		err = wrapError("failed to get rule by id", err)
+	} else {
+		rule, err = h.ruleRepo.GetByUrl(url)
+		err = wrapError("failed to get rule by url", err)
+	}
+	if err != nil {
+		if errors.Is(err, persistence.ErrNotFound) && onMissing == strconv.Itoa(http.StatusNoContent) {
+			c.Status(http.StatusNoContent)
 			return
 		}
-		c.JSON(http.StatusOK, rule)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	rule, err := h.ruleRepo.GetByUrl(url)
-	if err != nil {
-		c.JSON(http.StatusNotFound,
-			gin.H{"error": fmt.Sprintf("failed to get rule by url. %s", err.Error())})
+	if c.Query("include_freshness") == "true" {
+		c.JSON(http.StatusOK, h.freshnessResponse(rule, time.Now()))
 		return
 	}
-
-	c.JSON(http.StatusOK, rule)
+	c.JSON(http.StatusOK, h.ruleResponse(rule))
 }
 
 // CreateCustomRule godoc
 // @Summary Create a custom rule
-// @Description Create a new custom rule by providing a URL and the corresponding rule file
+// @Description Create a new custom rule by providing a URL and the corresponding rule file.
+// @Description If asynchronous writes are enabled, the rule is queued and the tracking id is returned.
 // @Tags Custom Rule
 // @Accept plain
 // @Produce json
 // @Param url query string true "URL for the custom rule"
+// @Param note query string false "Human-readable note on why the rule exists. Max 255 characters"
+// @Param Idempotency-Key header string false "Repeated requests with the same key return the original response"
 // @Param file body string true "Custom rule file content"
-// @Success 200 {object} string "Custom rule created successfully"
-// @Failure 400 {object} error "Bad request, missing 'url' or empty file"
+// @Success 200 {object} string "Custom rule created successfully. The id and the normalized domain are returned"
+// @Success 202 {object} string "Custom rule queued for creation. The tracking id and the normalized domain are returned"
+// @Failure 400 {object} error "Bad request, missing 'url', empty file or too long note"
 // @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Database is unavailable"
 // @Security ApiKeyAuth
 // @Router /custom-rule [post]
 func (h *RobotsHandler) CreateCustomRule(c *gin.Context) {
@@ -135,6 +298,21 @@ func (h *RobotsHandler) CreateCustomRule(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
 		return
 	}
+	note, ok := parseNote(c)
+	if !ok {
+		return
+	}
+
+	// the key is scoped by the api key, so different clients can't get each other's responses
+	var idempotencyKey string
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		idempotencyKey = c.GetHeader("X-API-Key") + ":" + key
+		if response, ok := h.cache.GetIdempotentResponse(idempotencyKey); ok {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(response.StatusCode, "application/json; charset=utf-8", response.Body)
+			return
+		}
+	}
 
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
@@ -146,23 +324,88 @@ func (h *RobotsHandler) CreateCustomRule(c *gin.Context) {
 		return
 	}
 
-	domain, err := util.GetDomain(url)
+	domain, err := h.getDomain(url)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse url. %s", err.Error())})
 		return
 	}
 
-	id, err := h.ruleRepo.Save(&model.Rule{
+	rule := &model.Rule{
 		Domain:    domain,
 		RobotsTxt: string(body),
-	})
+		Note:      note,
+	}
+	h.saveCustomRule(c, rule, idempotencyKey)
+}
+
+// saveCustomRule saves the new rule, or queues it if asynchronous writes are enabled, and responds with the id
+// and the domain of the rule.
+func (h *RobotsHandler) saveCustomRule(c *gin.Context, rule *model.Rule, idempotencyKey string) {
+	// the rule is saved with the warnings, they are returned so the author can fix the lines
+	warnings := util.ValidateRobotsTxt(rule.RobotsTxt).Warnings
+	if h.ruleQueue != nil {
+		trackingId, err := h.ruleQueue.Enqueue(rule)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable,
+				gin.H{"error": fmt.Sprintf("failed to queue custom rule. %s", err.Error())})
+			return
+		}
+		h.ruleCache.Invalidate(rule)
+		h.respondIdempotent(c, idempotencyKey, http.StatusAccepted,
+			withWarnings(gin.H{"tracking_id": trackingId, "domain": rule.Domain}, warnings))
+		return
+	}
+
+	id, err := h.ruleRepo.Save(rule)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
+		c.JSON(dbErrorStatus(err),
 			gin.H{"error": fmt.Sprintf("failed to save custom rule. %v", err.Error())})
 		return
 	}
+	// the new rule replaces the rule of the domain
+	h.ruleCache.Invalidate(rule)
 
-	c.JSON(http.StatusOK, gin.H{"id": id})
+	h.respondIdempotent(c, idempotencyKey, http.StatusOK,
+		withWarnings(gin.H{"id": id, "domain": rule.Domain}, warnings))
+}
+
+// withWarnings adds the validation warnings of the saved rule to the response if there are any.
+func withWarnings(response gin.H, warnings []util.RobotsTxtWarning) gin.H {
+	if len(warnings) > 0 {
+		response["warnings"] = warnings
+	}
+	return response
+}
+
+// GetCustomRuleStatus godoc
+// @Summary Get the status of an asynchronous custom rule creation
+// @Description Retrieve the state of the queued custom rule write by the tracking id returned from the create request
+// @Tags Custom Rule
+// @Produce json
+// @Param id query string true "Tracking ID"
+// @Success 200 {object} model.WriteStatus "Write status"
+// @Failure 400 {object} error "Bad request, missing 'id' or asynchronous writes are disabled"
+// @Failure 404 {object} error "Write not found"
+// @Security ApiKeyAuth
+// @Router /custom-rule/status [get]
+func (h *RobotsHandler) GetCustomRuleStatus(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'id' query parameter is required"})
+		return
+	}
+	if h.ruleQueue == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "asynchronous writes are disabled"})
+		return
+	}
+
+	status, ok := h.ruleQueue.Status(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("write with tracking id '%s' not found", id)})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
 }
 
 // UpdateCustomRule godoc
@@ -172,12 +415,14 @@ func (h *RobotsHandler) CreateCustomRule(c *gin.Context) {
 // @Accept plain
 // @Produce json
 // @Param id query string true "Custom rule ID"
-// @Param url query string true "New URL for the custom rule"
+// @Param url query string false "New URL for the custom rule. The domain is not changed if it is omitted"
+// @Param note query string false "New note for the custom rule. The note is not changed if it is omitted and removed if it is empty"
 // @Param file body string true "Updated custom rule file content"
-// @Success 200 {object} model.Rule "Updated custom rule"
-// @Failure 400 {object} error "Bad request, missing 'id' or invalid data to update"
+// @Success 200 {object} model.Rule "Updated custom rule. The unchanged rule with 'not_modified: true' if nothing changed"
+// @Failure 400 {object} error "Bad request, missing 'id', invalid data to update or too long note"
 // @Failure 404 {object} error "Rule not found"
 // @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Database is unavailable"
 // @Security ApiKeyAuth
 // @Router /custom-rule [put]
 func (h *RobotsHandler) UpdateCustomRule(c *gin.Context) {
@@ -189,17 +434,37 @@ func (h *RobotsHandler) UpdateCustomRule(c *gin.Context) {
 
 	rule, err := h.ruleRepo.GetById(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		status := http.StatusNotFound
+		if persistence.IsUnavailable(err) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
-	url := c.Query("url")
-	domain, err := util.GetDomain(url)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse url. %s", err.Error())})
-		return
+	modified := false
+	// the domain is kept if the url is not provided, so only the rule content is updated
+	if url := c.Query("url"); url != "" {
+		domain, err := h.getDomain(url)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse url. %s", err.Error())})
+			return
+		}
+		if domain != rule.Domain {
+			modified = true
+		}
+		rule.Domain = domain
+	}
+	if _, present := c.GetQuery("note"); present {
+		note, ok := parseNote(c)
+		if !ok {
+			return
+		}
+		if !equalNotes(note, rule.Note) {
+			modified = true
+		}
+		rule.Note = note
 	}
-	rule.Domain = domain
 
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
@@ -210,16 +475,128 @@ func (h *RobotsHandler) UpdateCustomRule(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "custom rules are not found or empty"})
 		return
 	}
+	if string(body) != rule.RobotsTxt {
+		modified = true
+	}
 	rule.RobotsTxt = string(body)
 
+	// the write is skipped for identical content, so updated_at is not bumped
+	if !modified {
+		c.JSON(http.StatusOK, h.notModifiedResponse(rule))
+		return
+	}
+
 	result, err := h.ruleRepo.Update(rule)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
+		c.JSON(dbErrorStatus(err),
 			gin.H{"error": fmt.Sprintf("failed to update custom rule. %v", err.Error())})
 		return
 	}
+	// the rule is invalidated by the id, so the entries of the previous domain are removed too
+	h.ruleCache.Invalidate(rule)
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, h.updatedResponse(result, util.ValidateRobotsTxt(rule.RobotsTxt).Warnings))
+}
+
+// validatedRule is the response of an update with the validation warnings of the saved rule.
+type validatedRule struct {
+	*model.Rule
+	Warnings []util.RobotsTxtWarning `json:"warnings,omitempty"`
+}
+
+type validatedCamelCaseRule struct {
+	*model.CamelCaseRule
+	Warnings []util.RobotsTxtWarning `json:"warnings,omitempty"`
+}
+
+func (h *RobotsHandler) updatedResponse(rule *model.Rule, warnings []util.RobotsTxtWarning) any {
+	if h.camelCase() {
+		return validatedCamelCaseRule{CamelCaseRule: rule.CamelCase(), Warnings: warnings}
+	}
+	return validatedRule{Rule: rule, Warnings: warnings}
+}
+
+// notModifiedRule is the response of an update that didn't change the rule.
+type notModifiedRule struct {
+	*model.Rule
+	NotModified bool `json:"not_modified"`
+}
+
+type notModifiedCamelCaseRule struct {
+	*model.CamelCaseRule
+	NotModified bool `json:"notModified"`
+}
+
+// ruleResponse returns the rule with the JSON field names configured by 'response.json_case'.
+func (h *RobotsHandler) ruleResponse(rule *model.Rule) any {
+	if h.camelCase() {
+		return rule.CamelCase()
+	}
+	return rule
+}
+
+func (h *RobotsHandler) notModifiedResponse(rule *model.Rule) any {
+	if h.camelCase() {
+		return notModifiedCamelCaseRule{CamelCaseRule: rule.CamelCase(), NotModified: true}
+	}
+	return notModifiedRule{Rule: rule, NotModified: true}
+}
+
+// freshRule is the rule with its age and the time since the last update, computed at the response time.
+// The fields are omitted if the timestamp is unknown (NULL in the legacy rows).
+type freshRule struct {
+	*model.Rule
+	AgeSeconds         *int64 `json:"age_seconds,omitempty"`
+	LastUpdatedSeconds *int64 `json:"last_updated_seconds,omitempty"`
+}
+
+type freshCamelCaseRule struct {
+	*model.CamelCaseRule
+	AgeSeconds         *int64 `json:"ageSeconds,omitempty"`
+	LastUpdatedSeconds *int64 `json:"lastUpdatedSeconds,omitempty"`
+}
+
+func (h *RobotsHandler) freshnessResponse(rule *model.Rule, now time.Time) any {
+	age := secondsSince(rule.CreatedAt, now)
+	lastUpdated := secondsSince(rule.UpdatedAt, now)
+	if h.camelCase() {
+		return freshCamelCaseRule{CamelCaseRule: rule.CamelCase(), AgeSeconds: age, LastUpdatedSeconds: lastUpdated}
+	}
+	return freshRule{Rule: rule, AgeSeconds: age, LastUpdatedSeconds: lastUpdated}
+}
+
+// secondsSince returns the seconds from t to now, or nil if t is the zero time.
+func secondsSince(t, now time.Time) *int64 {
+	if t.IsZero() {
+		return nil
+	}
+	seconds := int64(now.Sub(t).Seconds())
+	return &seconds
+}
+
+func (h *RobotsHandler) camelCase() bool {
+	return h.cfg.ResponseSettings != nil && h.cfg.ResponseSettings.JsonCase == config.JsonCaseCamel
+}
+
+func equalNotes(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// parseNote returns the 'note' query parameter, or nil if it is empty. It responds with 400 if the note is too long.
+func parseNote(c *gin.Context) (*string, bool) {
+	note := c.Query("note")
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		c.JSON(http.StatusBadRequest,
+			gin.H{"error": fmt.Sprintf("'note' must not be longer than %d characters", maxNoteLength)})
+		return nil, false
+	}
+	if note == "" {
+		return nil, true
+	}
+	return &note, true
 }
 
 // DeleteCustomRule godoc
@@ -231,6 +608,7 @@ func (h *RobotsHandler) UpdateCustomRule(c *gin.Context) {
 // @Success 200 {object} error "Rule deleted successfully"
 // @Failure 400 {object} error "Bad request, missing 'id'"
 // @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Database is unavailable"
 // @Security ApiKeyAuth
 // @Router /custom-rule [delete]
 func (h *RobotsHandler) DeleteCustomRule(c *gin.Context) {
@@ -242,63 +620,550 @@ func (h *RobotsHandler) DeleteCustomRule(c *gin.Context) {
 
 	err := h.ruleRepo.Delete(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
+		c.JSON(dbErrorStatus(err),
 			gin.H{"error": fmt.Sprintf("failed to delete custom rule. %v", err.Error())})
 		return
 	}
+	if ruleId, err := strconv.Atoi(id); err == nil {
+		h.ruleCache.Invalidate(&model.Rule{ID: ruleId})
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("rule with id '%s' is deleted", id)})
 }
 
-func (h *RobotsHandler) getRobotsTxt(url string) (string, error) {
+// setMaxAge sets 'Cache-Control: max-age' to the time the rules of the decision stay fresh: the remaining TTL of
+// the cached robots.txt, the full TTL of the robots.txt just fetched and cached, or 'robots.custom_rule_max_age'
+// for the custom rule. The header is omitted if the rules are not cached (e.g. the override or the default
+// robots.txt) or the remaining TTL is unknown.
+func (h *RobotsHandler) setMaxAge(c *gin.Context, resolved *resolvedRobotsTxt) {
+	var maxAge time.Duration
+	switch resolved.status {
+	case robotsStatusCustom:
+		maxAge = h.cfg.RobotsSettings.CustomRuleMaxAge
+	case robotsStatusCache:
+		maxAge = resolved.ttl
+	case robotsStatusOverride, "":
+		return
+	default:
+		code, err := strconv.Atoi(resolved.status)
+		if err != nil || !isSuccess(code) {
+			return
+		}
+		maxAge = h.cfg.CacheSettings.TtlForRobotsTxt
+	}
+	if maxAge <= 0 {
+		return
+	}
+	c.Header("Cache-Control", fmt.Sprintf("max-age=%d", int64(maxAge.Seconds())))
+}
+
+// decodeOverride decodes the robots.txt content of the X-Robots-Override header. The decoded content is limited
+// by 'http_client.max_robots_size', the same as the robots.txt files from origin.
+func (h *RobotsHandler) decodeOverride(override string) (string, error) {
+	maxSize := h.maxRobotsSize()
+	if int64(base64.StdEncoding.DecodedLen(len(override))) > maxSize+2 {
+		return "", fmt.Errorf("%s header exceeds the size limit of %d bytes", robotsOverrideHeader, maxSize)
+	}
+	robotsTxt, err := base64.StdEncoding.DecodeString(override)
+	if err != nil {
+		return "", fmt.Errorf("%s header must be base64-encoded. %s", robotsOverrideHeader, err.Error())
+	}
+	if int64(len(robotsTxt)) > maxSize {
+		return "", fmt.Errorf("%s header exceeds the size limit of %d bytes", robotsOverrideHeader, maxSize)
+	}
+	return string(robotsTxt), nil
+}
+
+// resolveRobotsTxt returns the robots.txt and the status resolved by resolve.
+func (h *RobotsHandler) resolveRobotsTxt(url string) (string, string, error) {
+	resolved, err := h.resolve(h.ruleRepo.GetByUrl, url)
+	return resolved.robotsTxt, resolved.status, err
+}
+
+// resolve looks up the rules for the url in the sources of 'robots.resolution_order' ('custom,cache,origin'
+// by default) and returns the first found: the custom rule (even if it is empty), the cached robots.txt or the origin
+// robots.txt. The origin is always the last source. The status is 'custom' for the custom rule, 'cache' for the cached
+// robots.txt, otherwise the status returned by fetchOrigin. The custom rule is read by getRule, e.g. GetByUrlBulk
+// for the bulk requests. The result is never nil.
+func (h *RobotsHandler) resolve(getRule ruleGetter, url string) (*resolvedRobotsTxt, error) {
+	for _, source := range h.resolution {
+		switch source {
+		case config.ResolutionCustom:
+			if rule := h.lookupCustomRule(getRule, url); rule != nil {
+				// an empty custom rule is an explicit decision to allow everything, so the origin is not requested
+				return &resolvedRobotsTxt{robotsTxt: rule.RobotsTxt, status: robotsStatusCustom}, nil
+			}
+		case config.ResolutionCache:
+			if file, ttl, ok := h.cache.GetRobotsFile(url); ok {
+				return &resolvedRobotsTxt{robotsTxt: file, status: robotsStatusCache, ttl: ttl}, nil
+			}
+		}
+	}
+
+	return h.fetchOrigin(url)
+}
+
+// ruleGetter reads the custom rule of the url from the database, e.g. RuleStorage.GetByUrl.
+type ruleGetter func(url string) (*model.Rule, error)
+
+// lookupCustomRule returns the custom rule for the url or nil if it doesn't exist. If the database is unavailable,
+// the rule is taken from the in-memory rule cache, otherwise nil is returned, so the origin robots.txt is used.
+func (h *RobotsHandler) lookupCustomRule(getRule ruleGetter, url string) *model.Rule {
+	// check the custom rule for the given url in database
+	rule, err := getRule(url)
+	domain, _ := h.getDomain(url)
+	switch {
+	case errors.Is(err, persistence.ErrNotFound):
+		h.ruleCache.Delete(domain)
+	case err != nil:
+		metrics.CustomRuleLookupErrors.Inc()
+		if cached, ok := h.ruleCache.Get(domain); ok {
+			slog.Warn("failed to get custom rule. Use the cached custom rule.", slog.String("url", url),
+				slog.String("err", err.Error()))
+			rule, err = cached, nil
+			break
+		}
+		// the scrape check doesn't depend on the database, so the origin robots.txt is used
+		slog.Warn("failed to get custom rule. Fall back to the origin robots.txt.", slog.String("url", url),
+			slog.String("err", err.Error()))
+	case rule != nil:
+		h.ruleCache.Put(domain, rule)
+	}
+	if err != nil || rule == nil {
+		return nil
+	}
+	validateCustomRule(rule)
+
+	return rule
+}
+
+// resolveAgentRobotsTxt returns the robots.txt and the status resolved by resolveAgent.
+func (h *RobotsHandler) resolveAgentRobotsTxt(url, userAgent string) (string, string, error) {
+	resolved, err := h.resolveAgent(h.ruleRepo.GetByUrl, url, userAgent)
+	return resolved.robotsTxt, resolved.status, err
+}
+
+// resolveAgent resolves the rules for the user agent: the group of the user agent in the custom rule,
+// then the '*' group of the custom rule, then the origin robots.txt. The origin is used only if
+// 'robots.custom_rule_fallback' is enabled and the custom rule has no group for the user agent. An empty custom rule
+// is an explicit decision to allow everything, so it is always used.
+func (h *RobotsHandler) resolveAgent(getRule ruleGetter, url, userAgent string) (*resolvedRobotsTxt, error) {
+	resolved, err := h.resolve(getRule, url)
+	if err != nil || resolved.status != robotsStatusCustom || !h.cfg.RobotsSettings.CustomRuleFallback ||
+		util.HasNoRules(resolved.robotsTxt) {
+		return resolved, err
+	}
+	if group := util.MatchGroup(resolved.robotsTxt, h.matcher.UserAgent(userAgent)); len(group.UserAgents) > 0 {
+		return resolved, nil
+	}
+	slog.Debug("custom rule has no group for the user agent. Fall back to the origin robots.txt.",
+		slog.String("url", url), slog.String("user_agent", userAgent))
+	return h.getRobotsTxt(url)
+}
+
+// getRobotsTxt returns the robots.txt file from cache or origin and its status.
+// The status is the origin status code, 'cache' or empty if the origin didn't respond.
+// Concurrent cache misses for the same origin are coalesced into one request.
+func (h *RobotsHandler) getRobotsTxt(url string) (*resolvedRobotsTxt, error) {
 	// check if the robots.txt file is already saved in cache
-	file, ok := h.cache.GetRobotsFile(url)
+	file, ttl, ok := h.cache.GetRobotsFile(url)
 	if ok {
-		return file, nil
+		return &resolvedRobotsTxt{robotsTxt: file, status: robotsStatusCache, ttl: ttl}, nil
+	}
+
+	return h.fetchOrigin(url)
+}
+
+// fetchOrigin fetches the robots.txt file from origin bypassing the cache, saves it to cache and returns it with
+// the status. Concurrent fetches for the same origin are coalesced into one request. The result is never nil.
+func (h *RobotsHandler) fetchOrigin(url string) (*resolvedRobotsTxt, error) {
+	key, err := util.GetBaseUrl(url)
+	if err != nil {
+		key = url
 	}
+	leader := false
+	result, err, _ := h.fetchGroup.Do(key, func() (any, error) {
+		leader = true
+		metrics.FetchOrigin.Inc()
+		return h.loadRobotsTxt(url)
+	})
+	if !leader {
+		metrics.FetchCoalesced.Inc()
+	}
+
+	return result.(*resolvedRobotsTxt), err
+}
+
+// resolvedRobotsTxt is the robots.txt resolved for the url with its status. The result of loadRobotsTxt is shared
+// by the coalesced requests.
+type resolvedRobotsTxt struct {
+	robotsTxt string
+	status    string
+	// truncated is set only for the robots.txt fetched from origin, the truncation of the cached robots.txt
+	// is not stored
+	truncated bool
+	// ttl is the remaining TTL of the cached robots.txt, 0 if it is unknown
+	ttl time.Duration
+}
+
+// loadRobotsTxt fetches the robots.txt file from origin and saves it to cache. The result is never nil,
+// so the status is returned with the error too.
+func (h *RobotsHandler) loadRobotsTxt(url string) (*resolvedRobotsTxt, error) {
 	// make get request to fetch the robots.txt file if it is not saved in cache
 	resp, err := h.requestToRobotsTxt(url)
+	fetched := &resolvedRobotsTxt{}
+	if resp != nil {
+		fetched.status = strconv.Itoa(resp.statusCode)
+	}
+	if errors.Is(err, errHtmlRobotsTxt) {
+		// soft 404 is handled as a missing robots.txt, that allows everything
+		h.cache.SaveRobotsFile(url, []byte{})
+		return fetched, nil
+	}
 	if err != nil {
-		return "", err
+		return fetched, err
+	}
+	if resp.statusCode == http.StatusNotFound && h.cfg.RobotsSettings.DefaultRobotsTxt != "" {
+		// the default policy is not cached, so a changed config is applied immediately
+		slog.Debug("robots.txt is not found. Use the default robots.txt.", slog.String("url", url))
+		fetched.robotsTxt = h.cfg.RobotsSettings.DefaultRobotsTxt
+		return fetched, nil
 	}
-	if resp == nil || len(resp) == 0 {
-		return "", fmt.Errorf("empty response")
+	if len(resp.body) == 0 {
+		return fetched, fmt.Errorf("empty response")
 	}
-	h.cache.SaveRobotsFile(url, resp)
+	h.cache.SaveRobotsFile(url, resp.body)
+	fetched.robotsTxt = string(resp.body)
+	fetched.truncated = resp.truncated
 
-	return string(resp), nil
+	return fetched, nil
+}
+
+// requestToRobotsTxt fetches the robots.txt file from origin. The response is nil if the origin didn't respond,
+// otherwise it is returned with the error too. The body is empty if the status code is not successful.
+func (h *RobotsHandler) requestToRobotsTxt(url string) (*robotsResponse, error) {
+	resp, err := h.fetchRobotsTxt(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.statusCode == http.StatusTooManyRequests {
+		slog.Warn("origin is rate limiting robots.txt requests.", slog.String("url", url),
+			slog.String("retry_after", resp.retryAfter))
+		return resp, &rateLimitedError{retryAfter: resp.retryAfter}
+	}
+	if !isSuccess(resp.statusCode) {
+		slog.Warn("status code not successful", slog.Int("code", resp.statusCode))
+		return resp, nil
+	}
+	if resp.truncated {
+		slog.Warn("robots.txt exceeds the size limit and is truncated.", slog.String("url", url),
+			slog.Int64("limit", h.maxRobotsSize()))
+	}
+	if h.cfg.RobotsSettings.DetectHtml && util.IsHtml(resp.contentType, resp.body) {
+		slog.Warn("robots.txt is served as html. Handle it as a missing robots.txt.", slog.String("url", url))
+		return resp, errHtmlRobotsTxt
+	}
+	return resp, nil
 }
 
-func (h *RobotsHandler) requestToRobotsTxt(url string) ([]byte, error) {
+// robotsResponse is the robots.txt response from origin. The body is read only for the successful status code.
+type robotsResponse struct {
+	statusCode  int
+	contentType string
+	retryAfter  string
+	body        []byte
+	// size is the Content-Length or the number of read bytes if the length is unknown
+	size      int64
+	truncated bool
+	// redirects is the chain of the followed redirects from the requested to the final url, empty without redirects
+	redirects []string
+}
+
+func (h *RobotsHandler) fetchRobotsTxt(url string) (*robotsResponse, error) {
 	baseUrl, err := util.GetBaseUrl(url)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("failed to parse url. %s", err.Error()))
 	}
 	req, err := http.NewRequest(http.MethodGet, baseUrl+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	maxSize := h.maxRobotsSize()
+	if h.cfg.HttpClientSettings.UseRange {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", maxSize-1))
+	}
+	// content-negotiating origins may serve a different robots.txt or a localized error page otherwise
+	if accept := h.cfg.HttpClientSettings.Accept; accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if acceptLanguage := h.cfg.HttpClientSettings.AcceptLanguage; acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
 	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		slog.Error(fmt.Sprintf("error making http get request to %s/robots.txt", baseUrl),
+			slog.String("err", err.Error()))
+		return nil, err
+	}
 	defer func(Body io.ReadCloser) {
 		err = resp.Body.Close()
 		if err != nil {
 			slog.Error("error closing response body", slog.String("err", err.Error()))
 		}
 	}(resp.Body)
-	if err != nil {
-		slog.Error(fmt.Sprintf("error making http get request to %s/robots.txt", baseUrl),
-			slog.String("err", err.Error()))
-		return nil, err
-	}
 
+	result := &robotsResponse{
+		statusCode:  resp.StatusCode,
+		contentType: resp.Header.Get("Content-Type"),
+		retryAfter:  resp.Header.Get("Retry-After"),
+		size:        resp.ContentLength,
+		redirects:   redirectChain(resp),
+	}
+	if len(result.redirects) > 0 {
+		slog.Info("robots.txt request is redirected.", slog.Any("chain", result.redirects),
+			slog.String("final_url", result.redirects[len(result.redirects)-1]))
+	}
 	if !isSuccess(resp.StatusCode) {
-		slog.Warn("status code not successful", slog.String("code", resp.Status))
-		return nil, err
+		return result, nil
 	}
 
-	b, err := io.ReadAll(resp.Body)
+	// the size is limited while reading, so it works for responses without Content-Length (e.g. chunked)
+	// and for origins that ignore the Range header and send the full body
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
 	if err != nil {
 		slog.Error("error reading response body", slog.String("err", err.Error()))
 		return nil, err
 	}
-	return b, nil
+	if resp.StatusCode == http.StatusPartialContent {
+		// the Content-Length of the partial response is the length of the range, not of the file
+		result.size = contentRangeSize(resp.Header.Get("Content-Range"))
+		result.truncated = result.size > int64(len(b))
+	}
+	if result.size < 0 {
+		result.size = int64(len(b))
+	}
+	if int64(len(b)) > maxSize {
+		result.truncated = true
+		b = b[:maxSize]
+	}
+	result.body = b
+	return result, nil
+}
+
+// redirectChain returns the urls of the followed redirects from the requested to the final url,
+// or nil if the response is not redirected. The redirect response that caused the request is kept in the request.
+func redirectChain(resp *http.Response) []string {
+	if resp.Request == nil || resp.Request.Response == nil {
+		return nil
+	}
+	var chain []string
+	for req := resp.Request; req != nil; {
+		chain = append(chain, util.RedactUserinfo(req.URL.String()))
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// contentRangeSize returns the complete length from the Content-Range header, e.g. 800000 for
+// 'bytes 0-511999/800000', or -1 if it is unknown.
+func contentRangeSize(contentRange string) int64 {
+	_, size, found := strings.Cut(contentRange, "/")
+	if !found {
+		return -1
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// maxRobotsSize returns the max size of robots.txt in bytes.
+func (h *RobotsHandler) maxRobotsSize() int64 {
+	return h.cfg.MaxRobotsSize()
+}
+
+// isUserAgentAllowed reports whether the user agent is in the configured allowlist. Any user agent is allowed
+// if the allowlist is empty. User agents are compared case-insensitively, as in robots.txt matching.
+func (h *RobotsHandler) isUserAgentAllowed(userAgent string) bool {
+	allowed := h.cfg.RobotsSettings.AllowedUserAgents
+	if len(allowed) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, userAgent) })
+}
+
+// respondIdempotent writes the JSON response and saves it for the idempotency key if the key is not empty.
+func (h *RobotsHandler) respondIdempotent(c *gin.Context, idempotencyKey string, code int, obj any) {
+	if idempotencyKey == "" {
+		c.JSON(code, obj)
+		return
+	}
+	body, err := json.Marshal(obj)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to marshal response. %s", err.Error())})
+		return
+	}
+	h.cache.SaveIdempotentResponse(idempotencyKey, &model.IdempotentResponse{StatusCode: code, Body: body})
+	c.Data(code, "application/json; charset=utf-8", body)
+}
+
+// loadErrorStatus returns 503 and sets the Retry-After header of the origin if the origin is rate limiting
+// robots.txt requests, 503 if the request to origin is over 'http_client.per_host_rate' or
+// 'http_client.max_concurrent_fetches', otherwise 500.
+// The unexpected errors are logged.
+func loadErrorStatus(c *gin.Context, err error) int {
+	var rateLimited *rateLimitedError
+	if errors.As(err, &rateLimited) {
+		if rateLimited.retryAfter != "" {
+			c.Header("Retry-After", rateLimited.retryAfter)
+		}
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, httpclient.ErrHostRateLimited) || errors.Is(err, httpclient.ErrFetchLimited) {
+		return http.StatusServiceUnavailable
+	}
+	slog.Error("failed to load robots.txt.", slog.String("err", err.Error()))
+	return http.StatusInternalServerError
+}
+
+// scrapeError writes the error of the scrape check. The decision is plain text, so the error is plain text
+// ('error: ...') by default, and JSON ({"error": "..."}) like the other endpoints if the client accepts only JSON
+// or prefers it in the Accept header.
+func scrapeError(c *gin.Context, code int, message string) {
+	if c.NegotiateFormat(binding.MIMEPlain, binding.MIMEJSON) == binding.MIMEJSON {
+		c.JSON(code, gin.H{"error": message})
+		return
+	}
+	c.String(code, fmt.Sprintf("error: %s", message))
+}
+
+// allowOnFetchError reports whether the url is allowed by 'robots.allow_on_fetch_error' although robots.txt couldn't
+// be fetched. The rate limiting errors keep 503 with Retry-After, so the client retries later.
+func (h *RobotsHandler) allowOnFetchError(err error) bool {
+	if !h.cfg.RobotsSettings.AllowOnFetchError {
+		return false
+	}
+	var rateLimited *rateLimitedError
+	return !errors.As(err, &rateLimited) && !errors.Is(err, httpclient.ErrHostRateLimited) &&
+		!errors.Is(err, httpclient.ErrFetchLimited)
+}
+
+// validateCustomRule reports the problems of the stored custom rule. The decision is not changed.
+func validateCustomRule(rule *model.Rule) {
+	report := util.ValidateRobotsTxt(rule.RobotsTxt)
+	if len(report.Warnings) == 0 {
+		return
+	}
+	slog.Warn("custom rule has validation warnings.", slog.Int("id", rule.ID), slog.String("domain", rule.Domain),
+		slog.Any("warnings", report.Warnings))
+	metrics.InvalidCustomRules.Inc()
+}
+
+// wrapError adds the message to the error, so it can still be checked with errors.Is. Returns nil if err is nil.
+func wrapError(message string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s. %w", message, err)
+}
+
+// dbErrorStatus returns 503 if the database is unavailable, otherwise 500. The unexpected errors are logged.
+func dbErrorStatus(err error) int {
+	if persistence.IsUnavailable(err) {
+		return http.StatusServiceUnavailable
+	}
+	slog.Error("database request failed.", slog.String("err", err.Error()))
+	return http.StatusInternalServerError
+}
+
+// requestUrl returns the normalized 'url' query parameter. A url with credentials is rejected with
+// util.ErrUrlUserinfo, or the credentials are removed if 'robots.strip_userinfo' is enabled, so they never reach
+// the logs and cache keys.
+func (h *RobotsHandler) requestUrl(c *gin.Context) (string, error) {
+	url := util.NormalizeUrl(c.Query("url"))
+	if !util.HasUserinfo(url) {
+		return url, nil
+	}
+	if h.cfg.RobotsSettings.StripUserinfo {
+		return util.StripUserinfo(url), nil
+	}
+	return "", util.ErrUrlUserinfo
+}
+
+// requestUserAgent returns the 'user_agent' query parameter, or 'robots.default_user_agent' if it is not sent.
+// An empty 'user_agent' is '*' if 'robots.empty_agent_as_wildcard' is enabled. The sent user agent must be
+// in the allowlist.
+func (h *RobotsHandler) requestUserAgent(c *gin.Context) (string, error) {
+	userAgent, present := c.GetQuery("user_agent")
+	if !present {
+		userAgent = h.cfg.RobotsSettings.DefaultUserAgent
+	} else if userAgent == "" && h.cfg.RobotsSettings.EmptyAgentAsWildcard {
+		// the wildcard is requested explicitly, so it is not checked against the allowlist
+		return "*", nil
+	}
+	if userAgent == "" {
+		return "", errors.New("'user_agent' query parameter is required")
+	}
+	// the configured default is trusted, so it is not checked against the allowlist
+	if present && !h.isUserAgentAllowed(userAgent) {
+		return "", fmt.Errorf("user agent '%s' is not in the allowlist", userAgent)
+	}
+	return userAgent, nil
+}
+
+// decisionETag returns the strong ETag of the scrape decision. The decision changes only with the robots.txt content
+// and the request, so the ETag is the hash of the robots.txt content hash, user agent, url and response format.
+func decisionETag(robotsTxt, userAgent, url string, explain bool) string {
+	contentHash := sha256.Sum256([]byte(robotsTxt))
+	hash := sha256.New()
+	hash.Write(contentHash[:])
+	for _, part := range []string{userAgent, url, strconv.FormatBool(explain)} {
+		hash.Write([]byte{0})
+		hash.Write([]byte(part))
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header matches the ETag. Weak validators are compared
+// by the opaque tag, as required for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// explain returns the rule that decided whether the url is allowed for the user agent and the confidence
+// of the decision. The confidence is low if the robots.txt has validation warnings or is truncated.
+func (h *RobotsHandler) explain(robotsTxt, userAgent, url string, truncated bool) *util.Explanation {
+	explanation := util.Explain(robotsTxt, h.matcher.UserAgent(userAgent), url)
+	explanation.Confidence = util.ConfidenceHigh
+	if truncated || len(util.ValidateRobotsTxt(robotsTxt).Warnings) > 0 {
+		explanation.Confidence = util.ConfidenceLow
+	}
+	return explanation
+}
+
+// decisionSource maps the robots.txt status to the source label of the scrape decision metric.
+func decisionSource(status string) string {
+	switch status {
+	case robotsStatusCustom:
+		return metrics.SourceCustom
+	case robotsStatusCache:
+		return metrics.SourceCache
+	case robotsStatusOverride:
+		return metrics.SourceOverride
+	default:
+		return metrics.SourceOrigin
+	}
 }
 
 func isSuccess(statusCode int) bool {
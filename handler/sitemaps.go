@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	u "net/url"
+	"slices"
+	"strings"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/jimsmart/grobotstxt"
+)
+
+const defaultMaxSitemaps = 1000
+
+// GetSitemaps godoc
+// @Summary Get the sitemaps of the robots.txt rules for the url
+// @Description Resolve the rules the same way as the scrape check (custom rule, cache or origin) and return
+// @Description the sitemaps. The number of sitemaps is limited by 'robots.max_sitemaps'
+// @Tags Scraping
+// @Produce json
+// @Param url query string true "URL to check"
+// @Success 200 {object} model.Sitemaps "Sitemaps and whether the list is truncated"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, missing 'url' or url with credentials"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /sitemaps [get]
+func (h *RobotsHandler) GetSitemaps(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+
+	robotsTxt, status, err := h.resolveRobotsTxt(url)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+
+	sitemaps, truncated := h.sitemaps(robotsTxt)
+	c.JSON(http.StatusOK, &model.Sitemaps{
+		Url:       url,
+		Source:    status,
+		Sitemaps:  sitemaps,
+		Truncated: truncated,
+	})
+}
+
+// GetAllowedSitemap godoc
+// @Summary Check if the sitemap is allowed to be fetched
+// @Description Check the robots.txt decision for the sitemap url and whether the sitemap is declared in robots.txt.
+// @Description The rules are resolved the same way as the scrape check (custom rule, cache or origin)
+// @Tags Scraping
+// @Produce json
+// @Param url query string true "URL of the sitemap"
+// @Param user_agent query string false "User agent to check. Required if 'robots.default_user_agent' is not configured"
+// @Success 200 {object} model.SitemapPermissions "Fetch permission and whether the sitemap is declared"
+// @Header 200,500 {string} X-Robots-Status "Origin status code of robots.txt, 'cache' or 'custom'"
+// @Failure 400 {object} error "Bad request, missing 'url' or 'user_agent', url with credentials, or the user agent is not in the allowlist"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Origin is rate limiting robots.txt requests. Retry-After of the origin is returned"
+// @Security ApiKeyAuth
+// @Router /sitemap-allowed [get]
+func (h *RobotsHandler) GetAllowedSitemap(c *gin.Context) {
+	url, err := h.requestUrl(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if url == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		return
+	}
+	userAgent, err := h.requestUserAgent(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	robotsTxt, status, err := h.resolveAgentRobotsTxt(url, userAgent)
+	if status != "" {
+		c.Header(robotsStatusHeader, status)
+	}
+	if err != nil {
+		c.JSON(loadErrorStatus(c, err), gin.H{"error": fmt.Sprintf("failed to load robots.txt. %s", err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, &model.SitemapPermissions{
+		FetchAllowed: h.matcher.AgentAllowed(robotsTxt, userAgent, url),
+		DeclaredInRobots: slices.ContainsFunc(grobotstxt.Sitemaps(robotsTxt), func(sitemap string) bool {
+			return sameUrl(sitemap, url)
+		}),
+	})
+}
+
+// sameUrl reports whether the urls are equal. The scheme and host are compared case-insensitively.
+func sameUrl(a, b string) bool {
+	parsedA, err := u.Parse(a)
+	if err != nil {
+		return false
+	}
+	parsedB, err := u.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsedA.Scheme, parsedB.Scheme) && strings.EqualFold(parsedA.Host, parsedB.Host) &&
+		parsedA.EscapedPath() == parsedB.EscapedPath() && parsedA.RawQuery == parsedB.RawQuery
+}
+
+// sitemaps returns the sitemaps of robots.txt limited by 'robots.max_sitemaps' and whether the limit is hit.
+func (h *RobotsHandler) sitemaps(robotsTxt string) ([]string, bool) {
+	limit := h.cfg.RobotsSettings.MaxSitemaps
+	if limit <= 0 {
+		limit = defaultMaxSitemaps
+	}
+	sitemaps := grobotstxt.Sitemaps(robotsTxt)
+	if sitemaps == nil {
+		return []string{}, false
+	}
+	if len(sitemaps) > limit {
+		return sitemaps[:limit], true
+	}
+	return sitemaps, false
+}
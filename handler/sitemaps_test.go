@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cacheMock "github.com/IliaW/robots-api/internal/cache/mocks"
+	"github.com/IliaW/robots-api/internal/persistence"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetSitemaps_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		sitemaps           int
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:     "sitemaps within the limit",
+			sitemaps: 2,
+			expectedResponse: "{\"url\":\"https://example.com/test\",\"source\":\"cache\",\"sitemaps\":[" +
+				"\"https://example.com/sitemap0.xml\",\"https://example.com/sitemap1.xml\"],\"truncated\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:     "sitemaps exceeding the limit are truncated",
+			sitemaps: 5,
+			expectedResponse: "{\"url\":\"https://example.com/test\",\"source\":\"cache\",\"sitemaps\":[" +
+				"\"https://example.com/sitemap0.xml\",\"https://example.com/sitemap1.xml\"," +
+				"\"https://example.com/sitemap2.xml\"],\"truncated\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "no sitemaps",
+			sitemaps:           0,
+			expectedResponse:   "{\"url\":\"https://example.com/test\",\"source\":\"cache\",\"sitemaps\":[],\"truncated\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			robotsTxt := "User-agent: *\nAllow: /\n"
+			for i := 0; i < test.sitemaps; i++ {
+				robotsTxt += fmt.Sprintf("Sitemap: https://example.com/sitemap%d.xml\n", i)
+			}
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Return(robotsTxt, time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+			cfg := testConfig()
+			cfg.RobotsSettings.MaxSitemaps = 3
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(cfg, cache, ruleRepo, nil, nil)
+			r.GET("/sitemaps", robotsHandler.GetSitemaps)
+			req, _ := http.NewRequest("GET", "/sitemaps?url=https://example.com/test", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_GetRobotsMeta_SitemapsTruncated_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	robotsTxt := "User-agent: *\nAllow: /\n" + strings.Repeat("Sitemap: https://example.com/sitemap.xml\n", 1001)
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", mock.Anything).Return(robotsTxt, time.Hour, true)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+
+	r := gin.Default()
+	// the default limit is 1000 if 'robots.max_sitemaps' is not set
+	robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, nil)
+	r.GET("/robots-meta", robotsHandler.GetRobotsMeta)
+	req, _ := http.NewRequest("GET", "/robots-meta?url=https://example.com/test", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1000, strings.Count(w.Body.String(), "https://example.com/sitemap.xml"))
+	assert.Contains(t, w.Body.String(), "\"sitemaps_truncated\":true")
+}
+
+func Test_GetAllowedSitemap_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	robotsTxt := "User-agent: *\nDisallow: /private\n" +
+		"Sitemap: https://example.com/sitemap.xml\n" +
+		"Sitemap: https://example.com/private/sitemap.xml\n"
+	testSet := []struct {
+		name               string
+		url                string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "declared sitemap",
+			url:                "https://example.com/sitemap.xml",
+			expectedResponse:   "{\"fetch_allowed\":true,\"declared_in_robots\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "declared sitemap with a different host case",
+			url:                "https://EXAMPLE.com/sitemap.xml",
+			expectedResponse:   "{\"fetch_allowed\":true,\"declared_in_robots\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "undeclared sitemap",
+			url:                "https://example.com/sitemap-news.xml",
+			expectedResponse:   "{\"fetch_allowed\":true,\"declared_in_robots\":false}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "declared disallowed sitemap",
+			url:                "https://example.com/private/sitemap.xml",
+			expectedResponse:   "{\"fetch_allowed\":false,\"declared_in_robots\":true}",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Return(robotsTxt, time.Hour, true)
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), cache, ruleRepo, nil, nil)
+			r.GET("/sitemap-allowed", robotsHandler.GetAllowedSitemap)
+			req, _ := http.NewRequest("GET", "/sitemap-allowed?url="+test.url+"&user_agent=bot", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
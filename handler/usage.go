@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/persistence"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler serves the API key usage accounting.
+type UsageHandler struct {
+	usageRepo persistence.UsageStorage
+}
+
+func NewUsageHandler(usageRepo persistence.UsageStorage) *UsageHandler {
+	return &UsageHandler{usageRepo: usageRepo}
+}
+
+// GetUsage godoc
+// @Summary Get the number of calls of every API key
+// @Description Retrieve the number of authenticated calls of every API key between the UTC days, both inclusive.
+// @Description The calls are saved periodically, so the last calls may be missing
+// @Tags Usage
+// @Produce json
+// @Param from query string true "First day, e.g. 2024-10-01"
+// @Param to query string true "Last day, e.g. 2024-10-31"
+// @Success 200 {array} model.ApiKeyUsage "Calls of every API key"
+// @Failure 400 {object} error "Bad request, missing or invalid 'from' or 'to'"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Database is unavailable"
+// @Security ApiKeyAuth
+// @Router /usage [get]
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	from, err := parseDay(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseDay(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must not be after 'to'"})
+		return
+	}
+
+	usage, err := h.usageRepo.GetUsage(from, to)
+	if err != nil {
+		c.JSON(dbErrorStatus(err), gin.H{"error": fmt.Sprintf("failed to get api key usage. %s", err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// parseDay returns the query parameter in the 'YYYY-MM-DD' format.
+func parseDay(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("'%s' query parameter is required", name)
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' query parameter must be a day in the 'YYYY-MM-DD' format", name)
+	}
+	return day, nil
+}
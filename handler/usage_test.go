@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_GetUsage_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		query              string
+		mockUsage          func() ([]*model.ApiKeyUsage, error)
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:  "usage of every key",
+			query: "from=2024-10-01&to=2024-10-31",
+			mockUsage: func() ([]*model.ApiKeyUsage, error) {
+				return []*model.ApiKeyUsage{
+					{ApiKey: "a1b2", Email: "user@mail.com", Calls: 42},
+					{ApiKey: "c3d4", Email: "", Calls: 7},
+				}, nil
+			},
+			expectedResponse: "[{\"api_key\":\"a1b2\",\"email\":\"user@mail.com\",\"calls\":42}," +
+				"{\"api_key\":\"c3d4\",\"email\":\"\",\"calls\":7}]",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "no usage",
+			query:              "from=2024-10-01&to=2024-10-01",
+			mockUsage:          func() ([]*model.ApiKeyUsage, error) { return []*model.ApiKeyUsage{}, nil },
+			expectedResponse:   "[]",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "missing from",
+			query:              "to=2024-10-31",
+			expectedResponse:   "{\"error\":\"'from' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid to",
+			query:              "from=2024-10-01&to=31.10.2024",
+			expectedResponse:   "{\"error\":\"'to' query parameter must be a day in the 'YYYY-MM-DD' format\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "from after to",
+			query:              "from=2024-11-01&to=2024-10-31",
+			expectedResponse:   "{\"error\":\"'from' must not be after 'to'\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "database error",
+			query:              "from=2024-10-01&to=2024-10-31",
+			mockUsage:          func() ([]*model.ApiKeyUsage, error) { return nil, errors.New("syntax error") },
+			expectedResponse:   "{\"error\":\"failed to get api key usage. syntax error\"}",
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			usageRepo := storageMock.NewUsageStorage(tt)
+			if test.mockUsage != nil {
+				usageRepo.On("GetUsage", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), mock.Anything).
+					Return(test.mockUsage())
+			}
+
+			r := gin.Default()
+			r.GET("/usage", NewUsageHandler(usageRepo).GetUsage)
+			req, _ := http.NewRequest("GET", "/usage?"+test.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// validateAllBatchSize is the number of rules loaded from the database at once.
+const validateAllBatchSize = 100
+
+// ValidateAllCustomRules godoc
+// @Summary Validate all stored custom rules
+// @Description Run the robots.txt validator on every stored custom rule and report the rules with warnings,
+// @Description e.g. to schedule a cleanup. The rules are loaded in batches, so the memory is bounded for large tables
+// @Tags Custom Rule
+// @Produce json
+// @Success 200 {object} model.RuleValidationReport "Number of checked rules and the rules with warnings"
+// @Failure 500 {object} error "Internal server error"
+// @Failure 503 {object} error "Database is unavailable"
+// @Security ApiKeyAuth
+// @Router /custom-rule/validate-all [get]
+func (h *RobotsHandler) ValidateAllCustomRules(c *gin.Context) {
+	report := &model.RuleValidationReport{Rules: []*model.RuleValidation{}}
+	afterId := 0
+	for {
+		rules, err := h.ruleRepo.ListRules(afterId, validateAllBatchSize)
+		if err != nil {
+			c.JSON(dbErrorStatus(err), gin.H{"error": fmt.Sprintf("failed to list custom rules. %s", err.Error())})
+			return
+		}
+		for _, rule := range rules {
+			report.Checked++
+			if warnings := util.ValidateRobotsTxt(rule.RobotsTxt).Warnings; len(warnings) > 0 {
+				report.Rules = append(report.Rules,
+					&model.RuleValidation{ID: rule.ID, Domain: rule.Domain, Warnings: warnings})
+			}
+		}
+		if len(rules) < validateAllBatchSize {
+			break
+		}
+		afterId = rules[len(rules)-1].ID
+	}
+	report.Invalid = len(report.Rules)
+
+	c.JSON(http.StatusOK, report)
+}
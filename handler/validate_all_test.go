@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IliaW/robots-api/internal/model"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateAllCustomRules_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		mockRules          []*model.Rule
+		mockError          error
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name: "valid and invalid rules",
+			mockRules: []*model.Rule{
+				{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /private"},
+				{ID: 2, Domain: "example.org", RobotsTxt: "Disallow: /private\nUser-agent: *\nAllow: /"},
+				{ID: 3, Domain: "example.net", RobotsTxt: ""},
+				{ID: 5, Domain: "example.io", RobotsTxt: "User-agent: *\nCrawl-delay: 10"},
+			},
+			expectedResponse: `{"checked":4,"invalid":2,"rules":[` +
+				`{"id":2,"domain":"example.org","warnings":[{"line":1,"column":1,"text":"Disallow: /private",` +
+				`"message":"disallow rule before any user-agent is ignored","severity":"error"}]},` +
+				`{"id":5,"domain":"example.io","warnings":[{"line":2,"column":1,"text":"Crawl-delay: 10",` +
+				`"message":"unsupported directive 'Crawl-delay' is ignored","severity":"error"}]}]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "no rules",
+			mockRules:          []*model.Rule{},
+			expectedResponse:   `{"checked":0,"invalid":0,"rules":[]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "database error",
+			mockError:          errors.New("connection refused"),
+			expectedResponse:   `{"error":"failed to list custom rules. connection refused"}`,
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("ListRules", 0, validateAllBatchSize).Return(test.mockRules, test.mockError)
+
+			r := gin.Default()
+			robotsHandler := NewRobotsHandler(testConfig(), nil, ruleRepo, nil, nil)
+			r.GET("/custom-rule/validate-all", robotsHandler.ValidateAllCustomRules)
+			req, _ := http.NewRequest("GET", "/custom-rule/validate-all", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_ValidateAllCustomRules_Batches_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	firstBatch := make([]*model.Rule, validateAllBatchSize)
+	for i := range firstBatch {
+		firstBatch[i] = &model.Rule{ID: i + 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /"}
+	}
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("ListRules", 0, validateAllBatchSize).Return(firstBatch, nil)
+	ruleRepo.On("ListRules", validateAllBatchSize, validateAllBatchSize).Return([]*model.Rule{
+		{ID: 150, Domain: "example.org", RobotsTxt: "Allow: /"},
+	}, nil)
+
+	r := gin.Default()
+	robotsHandler := NewRobotsHandler(testConfig(), nil, ruleRepo, nil, nil)
+	r.GET("/custom-rule/validate-all", robotsHandler.ValidateAllCustomRules)
+	req, _ := http.NewRequest("GET", "/custom-rule/validate-all", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"checked":101,"invalid":1,"rules":[{"id":150,"domain":"example.org"`)
+}
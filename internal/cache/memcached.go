@@ -7,72 +7,157 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/IliaW/robots-api/config"
+	"github.com/IliaW/robots-api/internal/model"
 	"github.com/IliaW/robots-api/util"
 	"github.com/bradfitz/gomemcache/memcache"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.50.0 --name CachedClient
 type CachedClient interface {
-	GetRobotsFile(string) (string, bool)
+	GetRobotsFile(string) (string, time.Duration, bool)
 	SaveRobotsFile(string, []byte)
+	Invalidate(string) error
+	GetIdempotentResponse(string) (*model.IdempotentResponse, bool)
+	SaveIdempotentResponse(string, *model.IdempotentResponse)
+	Servers() []model.CacheServer
+	Ping() error
 	Close()
 }
 
 type MemcachedClient struct {
-	client *memcache.Client
-	cfg    *config.CacheConfig
-	log    *slog.Logger
+	client       *memcache.Client
+	cfg          *config.CacheConfig
+	getDomain    util.DomainFunc
+	log          *slog.Logger
+	servers      []string
+	serverStatus map[string]model.CacheServer
+	statusMu     sync.RWMutex
+	done         chan struct{}
 }
 
-func NewMemcachedClient(cacheConfig *config.CacheConfig, log *slog.Logger) *MemcachedClient {
+// NewMemcachedClient connects to the memcached servers. If the servers don't respond, an error is returned
+// unless 'cache.start_degraded' is enabled. Then the client starts degraded: the cache calls fail until the servers
+// are reachable, and the health check reports when they recover.
+func NewMemcachedClient(cacheConfig *config.CacheConfig, getDomain util.DomainFunc,
+	log *slog.Logger) (*MemcachedClient, error) {
 	log.Info("connecting to memcached...")
 	ss := new(memcache.ServerList)
 	servers := strings.Split(cacheConfig.Servers, ",")
 	err := ss.SetServers(servers...)
 	if err != nil {
-		log.Error("failed to set memcached servers.", slog.String("err", err.Error()))
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to set memcached servers. %w", err)
 	}
 	c := &MemcachedClient{
-		client: memcache.NewFromSelector(ss),
-		cfg:    cacheConfig,
-		log:    log,
+		client:       memcache.NewFromSelector(ss),
+		cfg:          cacheConfig,
+		getDomain:    getDomain,
+		log:          log,
+		servers:      servers,
+		serverStatus: make(map[string]model.CacheServer),
+		done:         make(chan struct{}),
 	}
 	c.log.Info("pinging the memcached.")
-	err = c.client.Ping()
-	if err != nil {
-		log.Error("connection to the memcached is failed.", slog.String("err", err.Error()))
-		os.Exit(1)
+	if err = c.Ping(); err != nil {
+		if !cacheConfig.StartDegraded {
+			return nil, fmt.Errorf("connection to the memcached is failed. %w", err)
+		}
+		log.Warn("connection to the memcached is failed. Start without cache until it is reachable.",
+			slog.String("err", err.Error()))
+	} else {
+		c.log.Info("connected to memcached!")
 	}
-	c.log.Info("connected to memcached!")
+	c.checkServers()
+	go c.healthCheck()
 
-	return c
+	return c, nil
 }
 
-func (mc *MemcachedClient) GetRobotsFile(url string) (string, bool) {
+// Ping checks that all the servers respond.
+func (mc *MemcachedClient) Ping() error {
+	return mc.client.Ping()
+}
+
+// GetRobotsFile returns the cached robots.txt file for the url and its remaining TTL. The save time is stored in
+// the item flags, so the TTL is 0 for the items saved without it. Such items were saved by the older versions
+// as the JSON string of the base64 content and are decoded.
+func (mc *MemcachedClient) GetRobotsFile(url string) (string, time.Duration, bool) {
 	key := mc.generateDomainHash(url)
-	item, err := mc.client.Get(key)
+	item, err := mc.getWithTimeout(key)
 	if err != nil {
-		if errors.Is(err, memcache.ErrCacheMiss) {
+		if errors.Is(err, errReadTimeout) {
+			mc.log.Warn("cache read is slow. Treat it as a miss.", slog.String("key", key),
+				slog.Duration("read_timeout", mc.cfg.ReadTimeout))
+			return "", 0, false
+		} else if errors.Is(err, memcache.ErrCacheMiss) {
 			mc.log.Debug("cache not found.", slog.String("key", key))
-			return "", false
+			return "", 0, false
 		} else {
 			mc.log.Error("failed to check if scraped.", slog.String("key", key),
 				slog.String("err", err.Error()))
-			return "", false
+			return "", 0, false
 		}
 	}
 	mc.log.Debug("cache found.", slog.String("key", key))
+	ttl, known := remainingTtl(item.Flags, mc.cfg.TtlForRobotsTxt, time.Now())
+	if !known {
+		var robotsFile []byte
+		if err = json.Unmarshal(item.Value, &robotsFile); err == nil {
+			return string(robotsFile), ttl, true
+		}
+	}
+
+	return string(item.Value), ttl, true
+}
+
+var errReadTimeout = errors.New("cache read timed out")
 
-	return string(item.Value), true
+// getWithTimeout gets the item and gives up after 'cache.read_timeout'. The memcache client doesn't support
+// a context, so the get runs in a goroutine and is abandoned on timeout.
+func (mc *MemcachedClient) getWithTimeout(key string) (*memcache.Item, error) {
+	if mc.cfg.ReadTimeout <= 0 {
+		return mc.client.Get(key)
+	}
+	type result struct {
+		item *memcache.Item
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		item, err := mc.client.Get(key)
+		ch <- result{item, err}
+	}()
+	timer := time.NewTimer(mc.cfg.ReadTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.item, r.err
+	case <-timer.C:
+		return nil, errReadTimeout
+	}
 }
+
+// remainingTtl computes the remaining TTL of the item saved at the unix time of the flags. Zero flags mean
+// the save time is unknown.
+func remainingTtl(savedAt uint32, ttl time.Duration, now time.Time) (time.Duration, bool) {
+	if savedAt == 0 {
+		return 0, false
+	}
+	remaining := ttl - now.Sub(time.Unix(int64(savedAt), 0))
+	return max(min(remaining, ttl), 0), true
+}
+
+// SaveRobotsFile caches the robots.txt file for the url as is, so the cache hits return the same content
+// as the origin.
 func (mc *MemcachedClient) SaveRobotsFile(url string, robotFile []byte) {
 	key := mc.generateDomainHash(url)
-	if err := mc.set(key, robotFile, int32((mc.cfg.TtlForRobotsTxt).Seconds())); err != nil {
+	// the save time is kept in the flags to compute the remaining TTL, memcached doesn't return the expiration
+	savedAt := uint32(time.Now().Unix())
+	if err := mc.set(key, robotFile, int32((mc.cfg.TtlForRobotsTxt).Seconds()), savedAt); err != nil {
 		mc.log.Error("failed to save robots file to cache.", slog.String("key", key),
 			slog.String("err", err.Error()))
 		return
@@ -80,7 +165,73 @@ func (mc *MemcachedClient) SaveRobotsFile(url string, robotFile []byte) {
 	mc.log.Debug("robots file saved to cache.")
 }
 
+// Invalidate deletes the cached robots.txt file for the url. A missing cache entry is not an error.
+func (mc *MemcachedClient) Invalidate(url string) error {
+	key := mc.generateDomainHash(url)
+	err := mc.client.Delete(key)
+	if err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		mc.log.Error("failed to invalidate robots file in cache.", slog.String("key", key),
+			slog.String("err", err.Error()))
+		return err
+	}
+	mc.log.Debug("robots file invalidated in cache.", slog.String("key", key))
+
+	return nil
+}
+
+func (mc *MemcachedClient) GetIdempotentResponse(idempotencyKey string) (*model.IdempotentResponse, bool) {
+	key := fmt.Sprintf("%s-idempotency-key", hashURL(idempotencyKey))
+	item, err := mc.client.Get(key)
+	if err != nil {
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			mc.log.Error("failed to get idempotent response.", slog.String("key", key),
+				slog.String("err", err.Error()))
+		}
+		return nil, false
+	}
+	var response model.IdempotentResponse
+	if err = json.Unmarshal(item.Value, &response); err != nil {
+		mc.log.Error("failed to unmarshal idempotent response.", slog.String("key", key),
+			slog.String("err", err.Error()))
+		return nil, false
+	}
+	mc.log.Debug("idempotent response found.", slog.String("key", key))
+
+	return &response, true
+}
+
+func (mc *MemcachedClient) SaveIdempotentResponse(idempotencyKey string, response *model.IdempotentResponse) {
+	key := fmt.Sprintf("%s-idempotency-key", hashURL(idempotencyKey))
+	value, err := json.Marshal(response)
+	if err == nil {
+		err = mc.set(key, value, int32((mc.cfg.TtlForIdempotencyKey).Seconds()), 0)
+	}
+	if err != nil {
+		mc.log.Error("failed to save idempotent response to cache.", slog.String("key", key),
+			slog.String("err", err.Error()))
+		return
+	}
+	mc.log.Debug("idempotent response saved to cache.")
+}
+
+// Servers returns the configured servers with their reachability from the last health check.
+func (mc *MemcachedClient) Servers() []model.CacheServer {
+	mc.statusMu.RLock()
+	defer mc.statusMu.RUnlock()
+	servers := make([]model.CacheServer, 0, len(mc.servers))
+	for _, server := range mc.servers {
+		status, ok := mc.serverStatus[server]
+		if !ok {
+			status = model.CacheServer{Address: server}
+		}
+		servers = append(servers, status)
+	}
+
+	return servers
+}
+
 func (mc *MemcachedClient) Close() {
+	close(mc.done)
 	mc.log.Info("closing memcached connection.")
 	err := mc.client.Close()
 	if err != nil {
@@ -88,14 +239,52 @@ func (mc *MemcachedClient) Close() {
 	}
 }
 
-func (mc *MemcachedClient) set(key string, value any, expiration int32) error {
-	byteValue, err := json.Marshal(value)
-	if err != nil {
-		return err
+// defaultHealthCheckInterval is used if 'cache.health_check_interval' is not positive.
+const defaultHealthCheckInterval = 30 * time.Second
+
+func (mc *MemcachedClient) healthCheck() {
+	interval := mc.cfg.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mc.done:
+			return
+		case <-ticker.C:
+			mc.checkServers()
+		}
 	}
+}
+
+// checkServers pings every server separately, so one unreachable server is visible even if others respond.
+func (mc *MemcachedClient) checkServers() {
+	for _, server := range mc.servers {
+		status := model.CacheServer{Address: server, Reachable: true, LastChecked: time.Now()}
+		client := memcache.New(server)
+		if err := client.Ping(); err != nil {
+			status.Reachable = false
+			status.Error = err.Error()
+			mc.log.Warn("memcached server is not reachable.", slog.String("server", server),
+				slog.String("err", err.Error()))
+		}
+		_ = client.Close()
+		mc.statusMu.Lock()
+		if previous, ok := mc.serverStatus[server]; ok && !previous.Reachable && status.Reachable {
+			mc.log.Info("memcached server is reachable again.", slog.String("server", server))
+		}
+		mc.serverStatus[server] = status
+		mc.statusMu.Unlock()
+	}
+}
+
+func (mc *MemcachedClient) set(key string, value []byte, expiration int32, flags uint32) error {
 	item := &memcache.Item{
 		Key:        key,
-		Value:      byteValue,
+		Value:      value,
+		Flags:      flags,
 		Expiration: expiration,
 	}
 
@@ -104,7 +293,7 @@ func (mc *MemcachedClient) set(key string, value any, expiration int32) error {
 
 func (mc *MemcachedClient) generateDomainHash(url string) string {
 	var key string
-	domain, err := util.GetDomain(url)
+	domain, err := mc.getDomain(url)
 	if err != nil {
 		mc.log.Error("failed to parse url. Use full url as a key.", slog.String("url", url),
 			slog.String("err", err.Error()))
@@ -0,0 +1,302 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLog = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// startFakeMemcached starts a server that answers the memcached 'version' command used by Ping.
+func startFakeMemcached(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					if _, err := r.ReadString('\n'); err != nil {
+						return
+					}
+					_, _ = conn.Write([]byte("VERSION 1.6.0\r\n"))
+				}
+			}(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+// unusedAddress returns an address that refuses connections.
+func unusedAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := l.Addr().String()
+	_ = l.Close()
+	return addr
+}
+
+func Test_MemcachedClient_CheckServers(t *testing.T) {
+	upServer := startFakeMemcached(t)
+	downServer := unusedAddress(t)
+	mc := &MemcachedClient{
+		cfg:          &config.CacheConfig{},
+		log:          testLog,
+		servers:      []string{upServer, downServer},
+		serverStatus: make(map[string]model.CacheServer),
+	}
+
+	mc.checkServers()
+	servers := mc.Servers()
+
+	assert.Len(t, servers, 2)
+	assert.Equal(t, upServer, servers[0].Address)
+	assert.True(t, servers[0].Reachable)
+	assert.Empty(t, servers[0].Error)
+	assert.Equal(t, downServer, servers[1].Address)
+	assert.False(t, servers[1].Reachable)
+	assert.NotEmpty(t, servers[1].Error)
+	assert.False(t, servers[1].LastChecked.IsZero())
+}
+
+func Test_NewMemcachedClient_Startup(t *testing.T) {
+	testSet := []struct {
+		name              string
+		reachable         bool
+		startDegraded     bool
+		expectedError     bool
+		expectedReachable bool
+	}{
+		{name: "reachable server", reachable: true, expectedReachable: true},
+		{name: "unreachable server fails by default", reachable: false, expectedError: true},
+		{name: "unreachable server starts degraded", reachable: false, startDegraded: true, expectedReachable: false},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			server := unusedAddress(tt)
+			if test.reachable {
+				server = startFakeMemcached(tt)
+			}
+
+			mc, err := NewMemcachedClient(&config.CacheConfig{
+				Servers:             server,
+				HealthCheckInterval: time.Hour,
+				StartDegraded:       test.startDegraded,
+			}, util.GetDomain, testLog)
+
+			if test.expectedError {
+				assert.ErrorContains(tt, err, "connection to the memcached is failed")
+				assert.Nil(tt, mc)
+				return
+			}
+			assert.NoError(tt, err)
+			defer mc.Close()
+			assert.Equal(tt, test.expectedReachable, mc.Ping() == nil)
+			assert.Equal(tt, test.expectedReachable, mc.Servers()[0].Reachable)
+		})
+	}
+}
+
+func Test_NewMemcachedClient_DefaultHealthCheckInterval(t *testing.T) {
+	// the health check goroutine panics on a zero ticker interval, that crashes the test binary
+	mc, err := NewMemcachedClient(&config.CacheConfig{
+		Servers: startFakeMemcached(t),
+	}, util.GetDomain, testLog)
+	assert.NoError(t, err)
+	defer mc.Close()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, mc.Servers()[0].Reachable)
+}
+
+// startSlowMemcached starts a server that answers every command with a cache miss after the delay.
+func startSlowMemcached(t *testing.T, delay time.Duration) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					if _, err := r.ReadString('\n'); err != nil {
+						return
+					}
+					time.Sleep(delay)
+					_, _ = conn.Write([]byte("END\r\n"))
+				}
+			}(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func Test_MemcachedClient_GetRobotsFile_ReadTimeout(t *testing.T) {
+	server := startSlowMemcached(t, 300*time.Millisecond)
+	ss := new(memcache.ServerList)
+	assert.NoError(t, ss.SetServers(server))
+	client := memcache.NewFromSelector(ss)
+	client.Timeout = time.Second
+	mc := &MemcachedClient{
+		client:    client,
+		cfg:       &config.CacheConfig{ReadTimeout: 20 * time.Millisecond},
+		getDomain: util.GetDomain,
+		log:       testLog,
+	}
+
+	start := time.Now()
+	robotsTxt, _, ok := mc.GetRobotsFile("https://example.com")
+
+	assert.False(t, ok)
+	assert.Empty(t, robotsTxt)
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+}
+
+// startStoringMemcached starts a server that keeps the items of the memcached 'set' command and returns them
+// to the 'gets' command.
+func startStoringMemcached(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	var mu sync.Mutex
+	items := make(map[string]*memcache.Item)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					fields := strings.Fields(line)
+					switch {
+					case len(fields) == 5 && fields[0] == "set":
+						flags, _ := strconv.ParseUint(fields[2], 10, 32)
+						size, _ := strconv.Atoi(fields[4])
+						value := make([]byte, size+2)
+						if _, err = io.ReadFull(r, value); err != nil {
+							return
+						}
+						mu.Lock()
+						items[fields[1]] = &memcache.Item{Key: fields[1], Value: value[:size], Flags: uint32(flags)}
+						mu.Unlock()
+						_, _ = conn.Write([]byte("STORED\r\n"))
+					case len(fields) == 2 && fields[0] == "gets":
+						mu.Lock()
+						item, ok := items[fields[1]]
+						mu.Unlock()
+						if ok {
+							_, _ = fmt.Fprintf(conn, "VALUE %s %d %d 1\r\n%s\r\n", item.Key, item.Flags, len(item.Value),
+								item.Value)
+						}
+						_, _ = conn.Write([]byte("END\r\n"))
+					default:
+						_, _ = conn.Write([]byte("ERROR\r\n"))
+					}
+				}
+			}(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func Test_MemcachedClient_RobotsFile_RoundTrip(t *testing.T) {
+	robotsTxt := "User-agent: *\nDisallow: /private\n"
+	// json.Marshal of the robots.txt bytes
+	legacyValue := `"VXNlci1hZ2VudDogKgpEaXNhbGxvdzogL3ByaXZhdGUK"`
+	testSet := []struct {
+		name             string
+		save             func(tt *testing.T, mc *MemcachedClient)
+		expectedTtlKnown bool
+	}{
+		{
+			name: "saved robots.txt is returned as is",
+			save: func(_ *testing.T, mc *MemcachedClient) {
+				mc.SaveRobotsFile("https://example.com/page", []byte(robotsTxt))
+			},
+			expectedTtlKnown: true,
+		},
+		{
+			name: "robots.txt saved as a JSON string by an older version is decoded",
+			save: func(tt *testing.T, mc *MemcachedClient) {
+				key := mc.generateDomainHash("https://example.com/page")
+				assert.NoError(tt, mc.client.Set(&memcache.Item{Key: key, Value: []byte(legacyValue)}))
+			},
+			expectedTtlKnown: false,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			mc := &MemcachedClient{
+				client:    memcache.New(startStoringMemcached(tt)),
+				cfg:       &config.CacheConfig{TtlForRobotsTxt: time.Hour},
+				getDomain: util.GetDomain,
+				log:       testLog,
+			}
+			test.save(tt, mc)
+
+			cached, ttl, ok := mc.GetRobotsFile("https://example.com/other")
+
+			assert.True(tt, ok)
+			assert.Equal(tt, robotsTxt, cached)
+			assert.Equal(tt, test.expectedTtlKnown, ttl > 0)
+		})
+	}
+}
+
+func Test_RemainingTtl(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	testSet := []struct {
+		name             string
+		savedAt          uint32
+		expectedTtl      time.Duration
+		expectedTtlKnown bool
+	}{
+		{name: "just saved", savedAt: 1_700_000_000, expectedTtl: time.Hour, expectedTtlKnown: true},
+		{name: "saved ten minutes ago", savedAt: 1_700_000_000 - 600, expectedTtl: 50 * time.Minute,
+			expectedTtlKnown: true},
+		{name: "expired", savedAt: 1_700_000_000 - 7200, expectedTtl: 0, expectedTtlKnown: true},
+		{name: "saved in the future by a skewed clock", savedAt: 1_700_000_000 + 600, expectedTtl: time.Hour,
+			expectedTtlKnown: true},
+		{name: "unknown save time", savedAt: 0, expectedTtl: 0, expectedTtlKnown: false},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			ttl, ok := remainingTtl(test.savedAt, time.Hour, now)
+
+			assert.Equal(tt, test.expectedTtlKnown, ok)
+			assert.Equal(tt, test.expectedTtl, ttl)
+		})
+	}
+}
@@ -2,7 +2,12 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	model "github.com/IliaW/robots-api/internal/model"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
 
 // CachedClient is an autogenerated mock type for the CachedClient type
 type CachedClient struct {
@@ -14,8 +19,38 @@ func (_m *CachedClient) Close() {
 	_m.Called()
 }
 
+// GetIdempotentResponse provides a mock function with given fields: _a0
+func (_m *CachedClient) GetIdempotentResponse(_a0 string) (*model.IdempotentResponse, bool) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotentResponse")
+	}
+
+	var r0 *model.IdempotentResponse
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (*model.IdempotentResponse, bool)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.IdempotentResponse); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IdempotentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // GetRobotsFile provides a mock function with given fields: _a0
-func (_m *CachedClient) GetRobotsFile(_a0 string) (string, bool) {
+func (_m *CachedClient) GetRobotsFile(_a0 string) (string, time.Duration, bool) {
 	ret := _m.Called(_a0)
 
 	if len(ret) == 0 {
@@ -23,8 +58,9 @@ func (_m *CachedClient) GetRobotsFile(_a0 string) (string, bool) {
 	}
 
 	var r0 string
-	var r1 bool
-	if rf, ok := ret.Get(0).(func(string) (string, bool)); ok {
+	var r1 time.Duration
+	var r2 bool
+	if rf, ok := ret.Get(0).(func(string) (string, time.Duration, bool)); ok {
 		return rf(_a0)
 	}
 	if rf, ok := ret.Get(0).(func(string) string); ok {
@@ -33,13 +69,60 @@ func (_m *CachedClient) GetRobotsFile(_a0 string) (string, bool) {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) bool); ok {
+	if rf, ok := ret.Get(1).(func(string) time.Duration); ok {
 		r1 = rf(_a0)
 	} else {
-		r1 = ret.Get(1).(bool)
+		r1 = ret.Get(1).(time.Duration)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(string) bool); ok {
+		r2 = rf(_a0)
+	} else {
+		r2 = ret.Get(2).(bool)
+	}
+
+	return r0, r1, r2
+}
+
+// Invalidate provides a mock function with given fields: _a0
+func (_m *CachedClient) Invalidate(_a0 string) error {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Invalidate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Ping provides a mock function with no fields
+func (_m *CachedClient) Ping() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveIdempotentResponse provides a mock function with given fields: _a0, _a1
+func (_m *CachedClient) SaveIdempotentResponse(_a0 string, _a1 *model.IdempotentResponse) {
+	_m.Called(_a0, _a1)
 }
 
 // SaveRobotsFile provides a mock function with given fields: _a0, _a1
@@ -47,6 +130,26 @@ func (_m *CachedClient) SaveRobotsFile(_a0 string, _a1 []byte) {
 	_m.Called(_a0, _a1)
 }
 
+// Servers provides a mock function with no fields
+func (_m *CachedClient) Servers() []model.CacheServer {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Servers")
+	}
+
+	var r0 []model.CacheServer
+	if rf, ok := ret.Get(0).(func() []model.CacheServer); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.CacheServer)
+		}
+	}
+
+	return r0
+}
+
 // NewCachedClient creates a new instance of CachedClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCachedClient(t interface {
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+)
+
+const (
+	defaultDialTimeout  = 30 * time.Second
+	keepAlive           = 30 * time.Second
+	defaultMaxRedirects = 10
+)
+
+func NewHttpClient(cfg *config.HttpClientConfig) *http.Client {
+	var transport http.RoundTripper = newTransport(cfg)
+	if cfg.ReadIdleTimeout > 0 {
+		transport = &idleTimeoutTransport{next: transport, timeout: cfg.ReadIdleTimeout}
+	}
+	// the requests waiting for the per host rate don't hold a slot of the process-wide limit
+	if cfg.MaxConcurrentFetches > 0 {
+		transport = newFetchLimitTransport(transport, cfg.MaxConcurrentFetches)
+	}
+	if cfg.PerHostRate > 0 {
+		transport = &rateLimitTransport{
+			next:    transport,
+			limiter: newHostLimiter(cfg.PerHostRate),
+			maxWait: cfg.PerHostMaxWait,
+		}
+	}
+	return &http.Client{
+		Transport: &userAgentTransport{
+			next:      transport,
+			userAgent: cfg.UserAgent,
+		},
+		Timeout:       cfg.RequestTimeout,
+		CheckRedirect: checkRedirect(cfg.MaxRedirects),
+	}
+}
+
+// checkRedirect stops following redirects after the max number. The default is the same as in http.Client.
+func checkRedirect(maxRedirects int) func(*http.Request, []*http.Request) error {
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	return func(_ *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+}
+
+// newTransport returns the default transport with the configured connection timeouts. The request timeout covers
+// the whole request including the body read, so a slow DNS lookup, connect or TLS handshake can fail faster.
+func newTransport(cfg *config.HttpClientConfig) *http.Transport {
+	denied, err := parseNetworks(cfg.DeniedNetworks)
+	if err != nil {
+		slog.Error("failed to parse denied networks.", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = newCachingDialer(newDialer(cfg), net.DefaultResolver, cfg.DnsCacheTtl, denied).DialContext
+	if cfg.TlsHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TlsHandshakeTimeout
+	}
+	if cfg.InsecureSkipVerify {
+		slog.Warn("TLS certificate verification of origins is DISABLED. Use 'http_client.insecure_skip_verify' " +
+			"only for testing against self-signed origins, never in production.")
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return transport
+}
+
+func newDialer(cfg *config.HttpClientConfig) *net.Dialer {
+	timeout := cfg.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+	return &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: keepAlive,
+	}
+}
+
+// userAgentTransport sets the configured User-Agent header on every outbound request.
+type userAgentTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HttpClient_UserAgent(t *testing.T) {
+	testSet := []struct {
+		name              string
+		userAgent         string
+		expectedUserAgent string
+	}{
+		{
+			name:              "configured user agent is sent",
+			userAgent:         "RobotsApiBot-staging/1.0",
+			expectedUserAgent: "RobotsApiBot-staging/1.0",
+		},
+		{
+			name:              "default go user agent is sent when not configured",
+			userAgent:         "",
+			expectedUserAgent: "Go-http-client/1.1",
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			var receivedUserAgent string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedUserAgent = r.UserAgent()
+			}))
+			defer srv.Close()
+
+			client := NewHttpClient(&config.HttpClientConfig{
+				RequestTimeout: 5 * time.Second,
+				UserAgent:      test.userAgent,
+			})
+			resp, err := client.Get(srv.URL + "/robots.txt")
+			assert.NoError(tt, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(tt, test.expectedUserAgent, receivedUserAgent)
+		})
+	}
+}
+
+func Test_HttpClient_Timeouts(t *testing.T) {
+	testSet := []struct {
+		name                        string
+		dialTimeout                 time.Duration
+		tlsHandshakeTimeout         time.Duration
+		expectedDialTimeout         time.Duration
+		expectedTlsHandshakeTimeout time.Duration
+	}{
+		{
+			name:                        "configured timeouts",
+			dialTimeout:                 2 * time.Second,
+			tlsHandshakeTimeout:         3 * time.Second,
+			expectedDialTimeout:         2 * time.Second,
+			expectedTlsHandshakeTimeout: 3 * time.Second,
+		},
+		{
+			name:                        "default timeouts when not configured",
+			expectedDialTimeout:         30 * time.Second,
+			expectedTlsHandshakeTimeout: 10 * time.Second,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &config.HttpClientConfig{
+				RequestTimeout:      15 * time.Second,
+				DialTimeout:         test.dialTimeout,
+				TlsHandshakeTimeout: test.tlsHandshakeTimeout,
+			}
+
+			assert.Equal(tt, test.expectedDialTimeout, newDialer(cfg).Timeout)
+			assert.Equal(tt, test.expectedTlsHandshakeTimeout, newTransport(cfg).TLSHandshakeTimeout)
+			assert.Equal(tt, 15*time.Second, NewHttpClient(cfg).Timeout)
+		})
+	}
+}
+
+func Test_HttpClient_InsecureSkipVerify(t *testing.T) {
+	testSet := []struct {
+		name               string
+		insecureSkipVerify bool
+	}{
+		{name: "certificates are verified by default", insecureSkipVerify: false},
+		{name: "certificate verification is disabled", insecureSkipVerify: true},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			transport := newTransport(&config.HttpClientConfig{InsecureSkipVerify: test.insecureSkipVerify})
+
+			insecure := transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify
+			assert.Equal(tt, test.insecureSkipVerify, insecure)
+		})
+	}
+}
+
+func Test_HttpClient_MaxRedirects(t *testing.T) {
+	testSet := []struct {
+		name          string
+		maxRedirects  int
+		redirects     int
+		expectedError string
+	}{
+		{name: "redirects within the limit are followed", maxRedirects: 2, redirects: 2},
+		{name: "redirects over the limit are stopped", maxRedirects: 2, redirects: 3,
+			expectedError: "stopped after 2 redirects"},
+		{name: "default limit", maxRedirects: 0, redirects: 10},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hop, _ := strconv.Atoi(r.URL.Query().Get("hop"))
+				if hop < test.redirects {
+					http.Redirect(w, r, fmt.Sprintf("/robots.txt?hop=%d", hop+1), http.StatusFound)
+				}
+			}))
+			defer srv.Close()
+			client := NewHttpClient(&config.HttpClientConfig{
+				RequestTimeout: 5 * time.Second,
+				MaxRedirects:   test.maxRedirects,
+			})
+
+			resp, err := client.Get(srv.URL + "/robots.txt")
+
+			if test.expectedError != "" {
+				assert.ErrorContains(tt, err, test.expectedError)
+				return
+			}
+			assert.NoError(tt, err)
+			_ = resp.Body.Close()
+			assert.Equal(tt, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func Test_HttpClient_ReadIdleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\n"))
+		w.(http.Flusher).Flush()
+		// the origin stalls after the first bytes
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	client := NewHttpClient(&config.HttpClientConfig{
+		RequestTimeout:  5 * time.Second,
+		ReadIdleTimeout: 100 * time.Millisecond,
+	})
+	start := time.Now()
+	resp, err := client.Get(srv.URL + "/robots.txt")
+	assert.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	assert.ErrorIs(t, err, ErrReadIdleTimeout)
+	assert.Equal(t, "User-agent: *\n", string(body))
+	assert.Less(t, time.Since(start), time.Second)
+}
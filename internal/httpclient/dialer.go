@@ -0,0 +1,128 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrDeniedAddress is returned when the host resolves only to the addresses from the denied networks.
+var ErrDeniedAddress = errors.New("address is denied")
+
+// resolver is implemented by net.Resolver. It can be replaced in tests.
+type resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+type dnsEntry struct {
+	ips     []net.IPAddr
+	expires time.Time
+}
+
+// cachingDialer resolves the host itself to cache the resolved addresses for the ttl and to deny the connections
+// to the denied networks (e.g. private and loopback). The check is done on the resolved address, so a public
+// hostname resolving to the private address is denied too. The cache is disabled if the ttl is 0.
+type cachingDialer struct {
+	dialer   *net.Dialer
+	resolver resolver
+	ttl      time.Duration
+	denied   []*net.IPNet
+	mu       sync.Mutex
+	cache    map[string]dnsEntry
+}
+
+func newCachingDialer(dialer *net.Dialer, resolver resolver, ttl time.Duration,
+	denied []*net.IPNet) *cachingDialer {
+	return &cachingDialer{
+		dialer:   dialer,
+		resolver: resolver,
+		ttl:      ttl,
+		denied:   denied,
+		cache:    make(map[string]dnsEntry),
+	}
+}
+
+func (d *cachingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		if d.isDenied(ip.IP) {
+			lastErr = fmt.Errorf("%w: %s resolves to %s", ErrDeniedAddress, host, ip.IP)
+			continue
+		}
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no addresses found for %s", host)
+	}
+	return nil, lastErr
+}
+
+func (d *cachingDialer) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+	if d.ttl <= 0 {
+		return d.resolver.LookupIPAddr(ctx, host)
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	entry, ok := d.cache[host]
+	d.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	ips, err := d.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	for h, e := range d.cache {
+		if !now.Before(e.expires) {
+			delete(d.cache, h)
+		}
+	}
+	d.cache[host] = dnsEntry{ips: ips, expires: now.Add(d.ttl)}
+	d.mu.Unlock()
+
+	return ips, nil
+}
+
+func (d *cachingDialer) isDenied(ip net.IP) bool {
+	for _, network := range d.denied {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetworks parses the CIDR notations, e.g. '10.0.0.0/8'.
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
@@ -0,0 +1,100 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubResolver resolves every host to the ip and counts the lookups.
+type stubResolver struct {
+	ip      net.IP
+	lookups atomic.Int32
+}
+
+func (r *stubResolver) LookupIPAddr(_ context.Context, _ string) ([]net.IPAddr, error) {
+	r.lookups.Add(1)
+	return []net.IPAddr{{IP: r.ip}}, nil
+}
+
+func newTestClient(dialer *cachingDialer) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+			// every request dials a new connection
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+// hostUrl replaces the ip of the test server with the host name, so it is resolved by the dialer.
+func hostUrl(srv *httptest.Server, host string) string {
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	return "http://" + net.JoinHostPort(host, port) + "/robots.txt"
+}
+
+func Test_CachingDialer_DnsCache(t *testing.T) {
+	testSet := []struct {
+		name            string
+		ttl             time.Duration
+		expectedLookups int32
+	}{
+		{
+			name:            "second fetch uses the cached address",
+			ttl:             time.Minute,
+			expectedLookups: 1,
+		},
+		{
+			name:            "every fetch resolves the host when the cache is disabled",
+			ttl:             0,
+			expectedLookups: 2,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer srv.Close()
+			resolver := &stubResolver{ip: net.ParseIP("127.0.0.1")}
+			client := newTestClient(newCachingDialer(&net.Dialer{}, resolver, test.ttl, nil))
+
+			for i := 0; i < 2; i++ {
+				resp, err := client.Get(hostUrl(srv, "example.test"))
+				assert.NoError(tt, err)
+				_ = resp.Body.Close()
+			}
+
+			assert.Equal(tt, test.expectedLookups, resolver.lookups.Load())
+		})
+	}
+}
+
+func Test_CachingDialer_DeniedNetworks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	denied, err := parseNetworks([]string{"127.0.0.0/8", "10.0.0.0/8"})
+	assert.NoError(t, err)
+	resolver := &stubResolver{ip: net.ParseIP("127.0.0.1")}
+	client := newTestClient(newCachingDialer(&net.Dialer{}, resolver, time.Minute, denied))
+
+	// the cached address is checked too
+	for i := 0; i < 2; i++ {
+		_, err = client.Get(hostUrl(srv, "example.test"))
+		assert.ErrorIs(t, err, ErrDeniedAddress)
+	}
+	_, err = client.Get(srv.URL)
+	assert.ErrorIs(t, err, ErrDeniedAddress)
+	assert.Equal(t, int32(1), resolver.lookups.Load())
+}
+
+func Test_ParseNetworks_Invalid(t *testing.T) {
+	_, err := parseNetworks([]string{"10.0.0.0/8", "not-a-network"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/IliaW/robots-api/internal/metrics"
+)
+
+// ErrFetchLimited is returned when the request waits for 'http_client.max_concurrent_fetches' until its deadline.
+var ErrFetchLimited = errors.New("too many concurrent requests to origins")
+
+// fetchLimitTransport limits the number of the requests to origins in flight in the process, shared by the single
+// and bulk scrape checks. A request holds its slot until the response body is closed, and a request over the limit
+// waits for a slot up to its deadline.
+type fetchLimitTransport struct {
+	next  http.RoundTripper
+	slots chan struct{}
+}
+
+func newFetchLimitTransport(next http.RoundTripper, maxFetches int) *fetchLimitTransport {
+	return &fetchLimitTransport{next: next, slots: make(chan struct{}, maxFetches)}
+}
+
+func (t *fetchLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		metrics.FetchLimited.Inc()
+		return nil, fmt.Errorf("%w. %w", ErrFetchLimited, req.Context().Err())
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		<-t.slots
+		return nil, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, release: func() { <-t.slots }}
+	return resp, nil
+}
+
+// slotBody releases the slot of the request once the body is closed.
+type slotBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
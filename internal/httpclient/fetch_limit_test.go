@@ -0,0 +1,41 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FetchLimitTransport(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	transport := newFetchLimitTransport(next, 1)
+	request := func(timeout time.Duration) (*http.Response, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, "GET", "https://example.com/robots.txt", nil)
+		return transport.RoundTrip(req)
+	}
+
+	first, err := request(time.Second)
+	assert.NoError(t, err)
+
+	// the slot is held until the body of the first response is closed
+	_, err = request(20 * time.Millisecond)
+	assert.ErrorIs(t, err, ErrFetchLimited)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = first.Body.Close()
+	}()
+	second, err := request(time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, second.Body.Close())
+	// the repeated close doesn't release the slot of another request
+	assert.NoError(t, second.Body.Close())
+	assert.Empty(t, transport.slots)
+}
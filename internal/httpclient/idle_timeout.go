@@ -0,0 +1,62 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrReadIdleTimeout is returned when the origin sends no bytes of the response body for 'http_client.read_idle_timeout'.
+var ErrReadIdleTimeout = errors.New("no response bytes received within the read idle timeout")
+
+// idleTimeoutTransport aborts reading the response body if the origin stalls, e.g. sends the first bytes quickly
+// and then hangs, so the request fails before the whole request timeout is spent.
+type idleTimeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *idleTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = newIdleTimeoutBody(resp.Body, t.timeout)
+	return resp, nil
+}
+
+// idleTimeoutBody closes the body if no bytes are read for the timeout. The close unblocks the pending read,
+// that then fails with ErrReadIdleTimeout.
+type idleTimeoutBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration) *idleTimeoutBody {
+	b := &idleTimeoutBody{body: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.timedOut.Store(true)
+		_ = b.body.Close()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.timedOut.Load() {
+		return n, ErrReadIdleTimeout
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.body.Close()
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/metrics"
+)
+
+// ErrHostRateLimited is returned when the request would exceed 'http_client.per_host_rate' longer than the wait limit.
+var ErrHostRateLimited = errors.New("rate limit of requests to the host is exceeded")
+
+const rateLimitPeriod = time.Minute
+
+// hostLimiter limits the number of requests to every host to the rate per minute. The requests are spread evenly,
+// but a burst of up to the rate requests is allowed after an idle period (generic cell rate algorithm).
+type hostLimiter struct {
+	interval  time.Duration
+	tolerance time.Duration
+	mu        sync.Mutex
+	// next is the theoretical arrival time of the next request to the host
+	next      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newHostLimiter(rate int) *hostLimiter {
+	interval := rateLimitPeriod / time.Duration(rate)
+	return &hostLimiter{
+		interval:  interval,
+		tolerance: interval * time.Duration(rate-1),
+		next:      make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// reserve returns how long the request to the host must wait. If the wait is longer than maxWait, nothing is
+// reserved and false is returned with the wait.
+func (l *hostLimiter) reserve(host string, maxWait time.Duration) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+	next, ok := l.next[host]
+	if !ok || next.Before(now) {
+		next = now
+	}
+	wait := next.Add(-l.tolerance).Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+	l.next[host] = next.Add(l.interval)
+	return wait, true
+}
+
+// sweep removes the hosts without requests in the last period, so the map doesn't grow with every host ever seen.
+func (l *hostLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitPeriod {
+		return
+	}
+	l.lastSweep = now
+	for host, next := range l.next {
+		if next.Before(now) {
+			delete(l.next, host)
+		}
+	}
+}
+
+// rateLimitTransport delays the requests to the hosts over the rate limit by up to maxWait and the deadline of
+// the request. The requests that would wait longer are failed with ErrHostRateLimited without being sent.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *hostLimiter
+	maxWait time.Duration
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxWait := t.maxWait
+	if deadline, ok := req.Context().Deadline(); ok {
+		maxWait = min(maxWait, time.Until(deadline))
+	}
+	host := strings.ToLower(req.URL.Hostname())
+	wait, ok := t.limiter.reserve(host, maxWait)
+	if !ok {
+		metrics.HostRateLimited.Inc()
+		return nil, fmt.Errorf("%w: %s. Retry after %s", ErrHostRateLimited, host, wait.Round(time.Second))
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimitTransport_PerHost(t *testing.T) {
+	var mu sync.Mutex
+	sent := make(map[string]int)
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent[req.URL.Host]++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	client := &http.Client{Transport: &rateLimitTransport{next: next, limiter: newHostLimiter(3)}}
+
+	var throttled int
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get("https://a.example.com/robots.txt")
+		if err != nil {
+			assert.ErrorIs(t, err, ErrHostRateLimited)
+			throttled++
+			continue
+		}
+		_ = resp.Body.Close()
+	}
+	resp, err := client.Get("https://b.example.com/robots.txt")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, 2, throttled)
+	assert.Equal(t, map[string]int{"a.example.com": 3, "b.example.com": 1}, sent)
+}
+
+func Test_HostLimiter_Reserve(t *testing.T) {
+	now := time.Date(2024, 10, 5, 10, 0, 0, 0, time.UTC)
+	limiter := newHostLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	// a burst of the rate is allowed, then the requests are spread evenly
+	for i := 0; i < 2; i++ {
+		wait, ok := limiter.reserve("example.com", 0)
+		assert.True(t, ok)
+		assert.Equal(t, time.Duration(0), wait)
+	}
+	wait, ok := limiter.reserve("example.com", 0)
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+	wait, ok = limiter.reserve("example.com", time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	now = now.Add(2 * time.Minute)
+	wait, ok = limiter.reserve("example.com", 0)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+	assert.Len(t, limiter.next, 1)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
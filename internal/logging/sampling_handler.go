@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// SamplingHandler wraps a slog.Handler and passes only 1-in-N debug records to it.
+// Records with the info level and above are always passed.
+type SamplingHandler struct {
+	next    slog.Handler
+	rate    uint64
+	counter *atomic.Uint64
+}
+
+func NewSamplingHandler(next slog.Handler, rate int) *SamplingHandler {
+	if rate < 1 {
+		rate = 1
+	}
+	return &SamplingHandler{
+		next:    next,
+		rate:    uint64(rate),
+		counter: new(atomic.Uint64),
+	}
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level <= slog.LevelDebug && h.rate > 1 {
+		// the first debug record is always logged, then every N-th one
+		if (h.counter.Add(1)-1)%h.rate != 0 {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), rate: h.rate, counter: h.counter}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), rate: h.rate, counter: h.counter}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingHandler struct {
+	mu     sync.Mutex
+	counts map[slog.Level]int
+}
+
+func (h *countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *countingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[r.Level]++
+	return nil
+}
+
+func (h *countingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *countingHandler) WithGroup(string) slog.Handler { return h }
+
+func Test_SamplingHandler(t *testing.T) {
+	testSet := []struct {
+		name          string
+		rate          int
+		debugRecords  int
+		errorRecords  int
+		expectedDebug int
+		expectedError int
+	}{
+		{
+			name:          "sampling is disabled",
+			rate:          1,
+			debugRecords:  100,
+			errorRecords:  10,
+			expectedDebug: 100,
+			expectedError: 10,
+		},
+		{
+			name:          "invalid rate disables sampling",
+			rate:          0,
+			debugRecords:  100,
+			errorRecords:  10,
+			expectedDebug: 100,
+			expectedError: 10,
+		},
+		{
+			name:          "log 1 in 10 debug messages",
+			rate:          10,
+			debugRecords:  1000,
+			errorRecords:  50,
+			expectedDebug: 100,
+			expectedError: 50,
+		},
+		{
+			name:          "log 1 in 3 debug messages",
+			rate:          3,
+			debugRecords:  10,
+			errorRecords:  5,
+			expectedDebug: 4,
+			expectedError: 5,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			counter := &countingHandler{counts: make(map[slog.Level]int)}
+			log := slog.New(NewSamplingHandler(counter, test.rate))
+			for i := 0; i < test.debugRecords; i++ {
+				log.Debug("debug message")
+			}
+			for i := 0; i < test.errorRecords; i++ {
+				log.Error("error message")
+				log.Warn("warn message")
+			}
+
+			assert.Equal(tt, test.expectedDebug, counter.counts[slog.LevelDebug])
+			assert.Equal(tt, test.expectedError, counter.counts[slog.LevelError])
+			assert.Equal(tt, test.expectedError, counter.counts[slog.LevelWarn])
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+)
+
+// StackKey is the attribute with the call stack of the error records.
+const StackKey = "stack"
+
+// StackHandler wraps a slog.Handler and adds the call stack to the records with the error level and above,
+// e.g. for the errors of the 500 responses. Records that already have the stack (a recovered panic) are passed as is.
+type StackHandler struct {
+	next slog.Handler
+}
+
+func NewStackHandler(next slog.Handler) *StackHandler {
+	return &StackHandler{next: next}
+}
+
+func (h *StackHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *StackHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelError || hasStack(r) {
+		return h.next.Handle(ctx, r)
+	}
+	r = r.Clone()
+	r.AddAttrs(slog.String(StackKey, string(debug.Stack())))
+	return h.next.Handle(ctx, r)
+}
+
+func (h *StackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &StackHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *StackHandler) WithGroup(name string) slog.Handler {
+	return &StackHandler{next: h.next.WithGroup(name)}
+}
+
+func hasStack(r slog.Record) bool {
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == StackKey
+		return !found
+	})
+	return found
+}
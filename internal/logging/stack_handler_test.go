@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StackHandler(t *testing.T) {
+	var out bytes.Buffer
+	log := slog.New(NewStackHandler(slog.NewJSONHandler(&out, nil)))
+
+	log.Warn("warn message")
+	log.Error("error message")
+	log.Error("panic recovered.", slog.String(StackKey, "recovered stack"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3)
+	records := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		assert.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+	}
+	assert.NotContains(t, records[0], StackKey)
+	assert.Contains(t, records[1][StackKey], "Test_StackHandler")
+	assert.Equal(t, "recovered stack", records[2][StackKey])
+}
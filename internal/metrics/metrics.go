@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	CustomRuleLookupErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_custom_rule_lookup_errors_total",
+		Help: "The number of failed custom rule lookups, that fell back to the origin robots.txt.",
+	})
+	InvalidCustomRules = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_invalid_custom_rules_total",
+		Help: "The number of scrape checks decided by a custom rule with validation warnings.",
+	})
+	ScrapeDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "robots_scrape_decisions_total",
+		Help: "The number of scrape checks by the source of the robots.txt rules: custom, cache, origin or fetch_failed.",
+	}, []string{"source"})
+	FetchOrigin = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_fetch_origin_total",
+		Help: "The number of robots.txt cache misses, that led to a request to origin.",
+	})
+	FetchCoalesced = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_fetch_coalesced_total",
+		Help: "The number of robots.txt cache misses, that waited for the concurrent request to the same origin.",
+	})
+	HostRateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_fetch_host_rate_limited_total",
+		Help: "The number of requests to origin, that were not sent because of 'http_client.per_host_rate'.",
+	})
+	FetchLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_fetch_limited_total",
+		Help: "The number of requests to origin, that were not sent because of 'http_client.max_concurrent_fetches'.",
+	})
+	RequestsShed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "robots_requests_shed_total",
+		Help: "The number of requests rejected with 503 because of 'server.max_concurrent_requests'.",
+	})
+)
+
+// Sources of the robots.txt rules for the ScrapeDecisions counter.
+const (
+	SourceCustom   = "custom"
+	SourceCache    = "cache"
+	SourceOrigin   = "origin"
+	SourceOverride = "override"
+	// SourceFetchFailed is the decision allowed by default because robots.txt couldn't be fetched.
+	SourceFetchFailed = "fetch_failed"
+)
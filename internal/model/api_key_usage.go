@@ -0,0 +1,17 @@
+package model
+
+// ApiKeyUsage godoc
+// @Description Represents the number of calls made with an API key in the requested period
+// @Type ApiKeyUsage
+type ApiKeyUsage struct {
+	ApiKey string `json:"api_key"`
+	Email  string `json:"email"`
+	Calls  int64  `json:"calls"`
+}
+
+// UsageBucket is the number of calls made with the API key in the UTC day. The key is the sha256 hash of the API key.
+type UsageBucket struct {
+	ApiKey string
+	Day    string
+	Calls  int64
+}
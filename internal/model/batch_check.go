@@ -0,0 +1,26 @@
+package model
+
+// BatchCheckRequest godoc
+// @Description Represents the urls of any domains to check against their robots.txt rules
+// @Type BatchCheckRequest
+type BatchCheckRequest struct {
+	UserAgent string   `json:"user_agent"`
+	Urls      []string `json:"urls"`
+}
+
+// UrlDecision godoc
+// @Description Represents the scrape decision for one url of the batch. Allowed is false if the url has an error
+// @Type UrlDecision
+type UrlDecision struct {
+	Url     string `json:"url"`
+	Allowed bool   `json:"allowed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BatchCheckResult godoc
+// @Description Represents the batch summary and the decision of every url in the order of the request
+// @Type BatchCheckResult
+type BatchCheckResult struct {
+	BatchSummary
+	Results []UrlDecision `json:"results"`
+}
@@ -0,0 +1,32 @@
+package model
+
+// BatchSummary godoc
+// @Description Represents the outcome of a batch operation, so the partial failures can be handled programmatically
+// @Type BatchSummary
+type BatchSummary struct {
+	Total     int          `json:"total"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Errors    []BatchError `json:"errors"`
+}
+
+// BatchError godoc
+// @Description Represents the failure of one item of a batch operation. Index is the position of the item in the request
+// @Type BatchError
+type BatchError struct {
+	Index   int    `json:"index"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewBatchSummary returns the summary of the batch, where all items succeeded until an error is added.
+func NewBatchSummary(total int) BatchSummary {
+	return BatchSummary{Total: total, Succeeded: total, Errors: []BatchError{}}
+}
+
+// AddError records the failure of the item at the index.
+func (s *BatchSummary) AddError(index int, code, message string) {
+	s.Succeeded--
+	s.Failed++
+	s.Errors = append(s.Errors, BatchError{Index: index, Code: code, Message: message})
+}
@@ -0,0 +1,26 @@
+package model
+
+// CacheInvalidationRequest godoc
+// @Description Represents the urls and domains, which cached robots.txt files should be invalidated
+// @Type CacheInvalidationRequest
+type CacheInvalidationRequest struct {
+	Urls    []string `json:"urls"`
+	Domains []string `json:"domains"`
+}
+
+// CacheInvalidation godoc
+// @Description Represents the result of the cache invalidation for one url or domain
+// @Type CacheInvalidation
+type CacheInvalidation struct {
+	Entry       string `json:"entry"`
+	Invalidated bool   `json:"invalidated"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CacheInvalidationSummary godoc
+// @Description Represents the batch summary of the cache invalidation and the result of every url and domain
+// @Type CacheInvalidationSummary
+type CacheInvalidationSummary struct {
+	BatchSummary
+	Results []CacheInvalidation `json:"results"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// CacheRefresh godoc
+// @Description Represents the robots.txt file fetched from origin and saved to cache by the refresh
+// @Type CacheRefresh
+type CacheRefresh struct {
+	Url    string `json:"url"`
+	Status string `json:"status"`
+	Size   int    `json:"size"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// CacheServer godoc
+// @Description Represents a configured cache server and its reachability from the last health check
+// @Type CacheServer
+type CacheServer struct {
+	Address     string    `json:"address"`
+	Reachable   bool      `json:"reachable"`
+	Error       string    `json:"error,omitempty"`
+	LastChecked time.Time `json:"last_checked"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// DebugStats godoc
+// @Description Represents the runtime and database connection pool stats of the service
+// @Type DebugStats
+type DebugStats struct {
+	Goroutines        int    `json:"goroutines"`
+	HeapAllocBytes    uint64 `json:"heap_alloc_bytes"`
+	HeapObjects       uint64 `json:"heap_objects"`
+	SysBytes          uint64 `json:"sys_bytes"`
+	GcCycles          uint32 `json:"gc_cycles"`
+	GcPauseTotalMs    int64  `json:"gc_pause_total_ms"`
+	LastGcUnix        int64  `json:"last_gc_unix"`
+	DbOpenConnections int    `json:"db_open_connections"`
+	DbInUse           int    `json:"db_in_use"`
+	DbIdle            int    `json:"db_idle"`
+}
@@ -0,0 +1,10 @@
+package model
+
+import "encoding/json"
+
+// IdempotentResponse is the stored response of a request with the Idempotency-Key header.
+// It is returned as is when the request with the same key is repeated.
+type IdempotentResponse struct {
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body"`
+}
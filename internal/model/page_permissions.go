@@ -0,0 +1,9 @@
+package model
+
+// PagePermissions godoc
+// @Description Represents whether the page is allowed to be crawled by robots.txt and indexed by X-Robots-Tag header
+// @Type PagePermissions
+type PagePermissions struct {
+	CrawlAllowed bool `json:"crawl_allowed"`
+	IndexAllowed bool `json:"index_allowed"`
+}
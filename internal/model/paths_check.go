@@ -0,0 +1,10 @@
+package model
+
+// PathsCheckRequest godoc
+// @Description Represents the paths of one domain to check against its robots.txt rules
+// @Type PathsCheckRequest
+type PathsCheckRequest struct {
+	Domain    string   `json:"domain"`
+	UserAgent string   `json:"user_agent"`
+	Paths     []string `json:"paths"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "github.com/IliaW/robots-api/util"
+
+// RobotsAudit godoc
+// @Description Represents the health of the robots.txt file fetched from origin
+// @Type RobotsAudit
+type RobotsAudit struct {
+	Url              string                  `json:"url"`
+	Reachable        bool                    `json:"reachable"`
+	StatusCode       int                     `json:"status_code,omitempty"`
+	Size             int64                   `json:"size"`
+	MaxSize          int64                   `json:"max_size"`
+	ExceedsSizeLimit bool                    `json:"exceeds_size_limit"`
+	UserAgentGroups  int                     `json:"user_agent_groups"`
+	UserAgents       []string                `json:"user_agents"`
+	Sitemaps         []string                `json:"sitemaps"`
+	Warnings         []util.RobotsTxtWarning `json:"warnings"`
+	Redirects        []string                `json:"redirects"`
+	Error            string                  `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "github.com/IliaW/robots-api/util"
+
+// RobotsMeta godoc
+// @Description Represents the summary of the robots.txt rules used for the url
+// @Type RobotsMeta
+type RobotsMeta struct {
+	Url               string          `json:"url"`
+	Source            string          `json:"source"`
+	Size              int             `json:"size"`
+	UserAgentGroups   int             `json:"user_agent_groups"`
+	UserAgents        []string        `json:"user_agents"`
+	Sitemaps          []string        `json:"sitemaps"`
+	SitemapsTruncated bool            `json:"sitemaps_truncated"`
+	VisitTime         *util.VisitTime `json:"visit_time"`
+}
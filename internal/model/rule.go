@@ -9,6 +9,35 @@ type Rule struct {
 	ID        int       `json:"id"`
 	Domain    string    `json:"domain"`
 	RobotsTxt string    `json:"robots_txt"`
+	Note      *string   `json:"note"`
+	SourceUrl *string   `json:"source_url"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// RuleDomain godoc
+// @Description Represents the domain of a custom rule without the rule content
+// @Type RuleDomain
+type RuleDomain struct {
+	ID     int    `json:"id"`
+	Domain string `json:"domain"`
+}
+
+// CamelCaseRule godoc
+// @Description Represents a custom rule for a domain with camelCase field names ('response.json_case: camel')
+// @Type CamelCaseRule
+type CamelCaseRule struct {
+	ID        int       `json:"id"`
+	Domain    string    `json:"domain"`
+	RobotsTxt string    `json:"robotsTxt"`
+	Note      *string   `json:"note"`
+	SourceUrl *string   `json:"sourceUrl"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CamelCase returns the rule with camelCase JSON field names.
+func (r *Rule) CamelCase() *CamelCaseRule {
+	camelCaseRule := CamelCaseRule(*r)
+	return &camelCaseRule
+}
@@ -0,0 +1,21 @@
+package model
+
+// RuleDiff godoc
+// @Description Represents the line-level diff of the custom rule (removed lines) and the live origin robots.txt
+// @Description (added lines)
+// @Type RuleDiff
+type RuleDiff struct {
+	RuleID    int        `json:"rule_id"`
+	SourceUrl string     `json:"source_url"`
+	Identical bool       `json:"identical"`
+	Diff      []DiffLine `json:"diff"`
+}
+
+// DiffLine godoc
+// @Description Represents a line of the diff. Op is 'equal', 'removed' (only in the custom rule) or 'added'
+// @Description (only in the origin robots.txt)
+// @Type DiffLine
+type DiffLine struct {
+	Op   string `json:"op"`
+	Line string `json:"line"`
+}
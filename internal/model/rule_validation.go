@@ -0,0 +1,21 @@
+package model
+
+import "github.com/IliaW/robots-api/util"
+
+// RuleValidationReport godoc
+// @Description Represents the validation of all stored custom rules. Only the rules with warnings are listed
+// @Type RuleValidationReport
+type RuleValidationReport struct {
+	Checked int               `json:"checked"`
+	Invalid int               `json:"invalid"`
+	Rules   []*RuleValidation `json:"rules"`
+}
+
+// RuleValidation godoc
+// @Description Represents the validation warnings of a stored custom rule
+// @Type RuleValidation
+type RuleValidation struct {
+	ID       int                     `json:"id"`
+	Domain   string                  `json:"domain"`
+	Warnings []util.RobotsTxtWarning `json:"warnings"`
+}
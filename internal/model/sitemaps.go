@@ -0,0 +1,19 @@
+package model
+
+// Sitemaps godoc
+// @Description Represents the sitemaps listed in the robots.txt rules used for the url
+// @Type Sitemaps
+type Sitemaps struct {
+	Url       string   `json:"url"`
+	Source    string   `json:"source"`
+	Sitemaps  []string `json:"sitemaps"`
+	Truncated bool     `json:"truncated"`
+}
+
+// SitemapPermissions godoc
+// @Description Represents whether the sitemap is allowed to be fetched by robots.txt and is declared in it
+// @Type SitemapPermissions
+type SitemapPermissions struct {
+	FetchAllowed     bool `json:"fetch_allowed"`
+	DeclaredInRobots bool `json:"declared_in_robots"`
+}
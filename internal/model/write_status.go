@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+const (
+	WriteStatusPending = "pending"
+	WriteStatusDone    = "done"
+	WriteStatusFailed  = "failed"
+)
+
+// WriteStatus godoc
+// @Description Represents the state of an asynchronous custom rule write
+// @Type WriteStatus
+type WriteStatus struct {
+	TrackingID string    `json:"tracking_id"`
+	Status     string    `json:"status"`
+	RuleID     int64     `json:"rule_id,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package persistence
+
+// BulkLimiter limits the number of database connections held concurrently by the bulk operations (batch saves,
+// purges, usage flushes and the reads of the bulk requests), so they can't take all the connections of the pool
+// ('database.max_open_conns') and the latency-sensitive reads of the scrape check always have connections left.
+// A nil BulkLimiter doesn't limit anything.
+type BulkLimiter struct {
+	slots chan struct{}
+}
+
+// NewBulkLimiter returns the limiter for the max number of connections, or nil if it is not positive.
+func NewBulkLimiter(maxConns int) *BulkLimiter {
+	if maxConns <= 0 {
+		return nil
+	}
+	return &BulkLimiter{slots: make(chan struct{}, maxConns)}
+}
+
+// acquire blocks until the bulk operation can take a connection. The returned function releases it.
+func (l *BulkLimiter) acquire() func() {
+	if l == nil {
+		return func() {}
+	}
+	l.slots <- struct{}{}
+	return func() { <-l.slots }
+}
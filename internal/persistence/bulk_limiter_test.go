@@ -0,0 +1,108 @@
+package persistence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BulkLimiter_ReadsProceed(t *testing.T) {
+	release := make(chan struct{})
+	blocked := make(chan struct{}, 3)
+	fake := &fakeDb{
+		exec: func(query string) {
+			if strings.HasPrefix(query, "DELETE") {
+				blocked <- struct{}{}
+				<-release
+			}
+		},
+		query: func(string) [][]driver.Value {
+			return [][]driver.Value{{int64(1), "example.com", "User-agent: *", nil, nil, nil, nil}}
+		},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	db.SetMaxOpenConns(2)
+	ruleRepo := NewRuleRepository(db, nil, false, false, NewBulkLimiter(1), slog.Default())
+
+	var purges sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		purges.Add(1)
+		go func() {
+			defer purges.Done()
+			_, _ = ruleRepo.PurgeDeleted(time.Now())
+		}()
+	}
+	<-blocked
+	// let the other purges try to take a connection
+	time.Sleep(50 * time.Millisecond)
+
+	// the purges hold one connection, so the read gets the other one
+	read := make(chan error)
+	go func() {
+		_, err := ruleRepo.GetById("1")
+		read <- err
+	}()
+	select {
+	case err := <-read:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("read is blocked by the bulk operations")
+	}
+	assert.Len(t, blocked, 0)
+
+	close(release)
+	purges.Wait()
+	assert.Len(t, fake.execs, 3)
+}
+
+func Test_BulkLimiter_BulkReadsWait(t *testing.T) {
+	release := make(chan struct{})
+	blocked := make(chan struct{}, 1)
+	fake := &fakeDb{
+		exec: func(string) {
+			blocked <- struct{}{}
+			<-release
+		},
+		query: func(string) [][]driver.Value {
+			return [][]driver.Value{{int64(1), "example.com", "User-agent: *", nil, nil, nil, nil}}
+		},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	ruleRepo := NewRuleRepository(db, util.GetDomain, false, false, NewBulkLimiter(1), slog.Default())
+	go func() {
+		_, _ = ruleRepo.PurgeDeleted(time.Now())
+	}()
+	<-blocked
+
+	bulkRead := make(chan error, 1)
+	go func() {
+		_, err := ruleRepo.GetByUrlBulk("https://example.com/")
+		bulkRead <- err
+	}()
+	// the read of the scrape check proceeds, the read of the bulk request waits for the purge
+	_, err := ruleRepo.GetByUrl("https://example.com/")
+	assert.NoError(t, err)
+	select {
+	case <-bulkRead:
+		t.Fatal("bulk read doesn't wait for the bulk operations")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(t, <-bulkRead)
+}
+
+func Test_BulkLimiter_Nil(t *testing.T) {
+	assert.Nil(t, NewBulkLimiter(0))
+	var limiter *BulkLimiter
+	limiter.acquire()()
+}
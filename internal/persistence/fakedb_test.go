@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// fakeDb is an in-memory database/sql connector that records the executed statements and queries.
+// Queries return the rows provided by the query function.
+type fakeDb struct {
+	execs        []fakeExec
+	queried      []fakeExec
+	rowsAffected int64
+	query        func(query string) [][]driver.Value
+	// exec is called before the statement is recorded, e.g. to block it
+	exec func(query string)
+	mu   sync.Mutex
+}
+
+type fakeExec struct {
+	query string
+	args  []any
+}
+
+func (d *fakeDb) Connect(context.Context) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+func (d *fakeDb) Driver() driver.Driver { return nil }
+
+// queries returns the executed statements.
+func (d *fakeDb) queries() []string {
+	queries := make([]string, 0, len(d.execs))
+	for _, exec := range d.execs {
+		queries = append(queries, exec.query)
+	}
+	return queries
+}
+
+type fakeConn struct {
+	db *fakeDb
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.db.exec != nil {
+		c.db.exec(query)
+	}
+	exec := fakeExec{query: query}
+	for _, arg := range args {
+		exec.args = append(exec.args, arg.Value)
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.execs = append(c.db.execs, exec)
+	return driver.RowsAffected(c.db.rowsAffected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queried := fakeExec{query: query}
+	for _, arg := range args {
+		queried.args = append(queried.args, arg.Value)
+	}
+	c.db.mu.Lock()
+	c.db.queried = append(c.db.queried, queried)
+	c.db.mu.Unlock()
+	var rows [][]driver.Value
+	if c.db.query != nil {
+		rows = c.db.query(query)
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return []string{"value"}
+	}
+	columns := make([]string, len(r.rows[0]))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i)
+	}
+	return columns
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,220 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// migrationLock is the name of the MySQL lock that lets only one replica apply the migrations.
+	migrationLock = "schema_migrations"
+	// migrationLockTimeout is how long in seconds a replica waits for another one to finish the migrations.
+	migrationLockTimeout = 60
+	// baselineVersion is the migration that created the schema before the migrations were tracked.
+	baselineVersion = "2024-11-04__init"
+	// baselineTable is the table of the baseline migration that shows the schema already exists.
+	baselineTable = "custom_rule"
+)
+
+// appliedErrors are the MySQL errors of DDL statements whose result already exists: table exists (1050),
+// duplicate column (1060), duplicate key name (1061) and trigger exists (1359).
+var appliedErrors = []uint16{1050, 1060, 1061, 1359}
+
+// Migrate applies the pending SQL migrations in the lexical order of the file names. The version of a migration is
+// its file name without the extension. Applied versions are tracked in the schema_migrations table.
+//
+// The migrations are applied under the MySQL named lock, so concurrent replicas apply them once. The schema created
+// before the migrations were tracked is adopted: the baseline migration is saved as applied without running it.
+// DDL statements whose result already exists are skipped, so a migration interrupted halfway can be re-run.
+func Migrate(db *sql.DB, migrations fs.FS, log *slog.Logger) error {
+	ctx := context.Background()
+	// the named lock belongs to the session, so all statements are run on one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection for the migrations. %w", err)
+	}
+	defer conn.Close()
+
+	if err = lockMigrations(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		var released sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLock).Scan(&released); err != nil {
+			log.Warn("failed to release the migration lock.", slog.String("err", err.Error()))
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(255) PRIMARY KEY, "+
+		"applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)")
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table. %w", err)
+	}
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err = adoptBaseline(ctx, conn, applied, log); err != nil {
+		return err
+	}
+	files, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		return err
+	}
+	slices.Sort(files)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(file, ".sql")
+		if applied[version] {
+			continue
+		}
+		script, err := fs.ReadFile(migrations, file)
+		if err != nil {
+			return err
+		}
+		for _, statement := range splitStatements(string(script)) {
+			if isSessionSetup(statement) {
+				continue
+			}
+			if _, err = conn.ExecContext(ctx, statement); err != nil {
+				if !isAlreadyApplied(err) {
+					return fmt.Errorf("failed to apply migration '%s'. %w", version, err)
+				}
+				log.Warn("migration statement is already applied. Skip it.", slog.String("version", version),
+					slog.String("err", err.Error()))
+			}
+		}
+		if err = saveMigration(ctx, conn, version); err != nil {
+			return err
+		}
+		log.Info("migration applied.", slog.String("version", version))
+	}
+
+	return nil
+}
+
+func lockMigrations(ctx context.Context, conn *sql.Conn) error {
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLock, migrationLockTimeout).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("failed to lock the migrations. %w", err)
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("failed to lock the migrations. The lock is held by another replica for more than %ds",
+			migrationLockTimeout)
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations. %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// adoptBaseline saves the baseline migration as applied if no migration is tracked yet, but the schema exists,
+// e.g. the database was created by the init script of the MySQL docker entrypoint before the migrations were tracked.
+func adoptBaseline(ctx context.Context, conn *sql.Conn, applied map[string]bool, log *slog.Logger) error {
+	if len(applied) > 0 {
+		return nil
+	}
+	var tables int
+	err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM information_schema.tables "+
+		"WHERE table_schema = DATABASE() AND table_name = ?", baselineTable).Scan(&tables)
+	if err != nil {
+		return fmt.Errorf("failed to check the existing schema. %w", err)
+	}
+	if tables == 0 {
+		return nil
+	}
+	if err = saveMigration(ctx, conn, baselineVersion); err != nil {
+		return err
+	}
+	applied[baselineVersion] = true
+	log.Info("existing schema adopted.", slog.String("version", baselineVersion))
+	return nil
+}
+
+func saveMigration(ctx context.Context, conn *sql.Conn, version string) error {
+	if _, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+		return fmt.Errorf("failed to save migration '%s'. %w", version, err)
+	}
+	return nil
+}
+
+// isSessionSetup reports whether the statement prepares the mysql client session, e.g. 'USE url_scraper' of the init
+// script written for the MySQL docker entrypoint. The migrations are applied to the database of the connection.
+func isSessionSetup(statement string) bool {
+	fields := strings.Fields(strings.ToUpper(statement))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "USE", "GRANT":
+		return true
+	case "CREATE":
+		return len(fields) > 1 && (fields[1] == "SCHEMA" || fields[1] == "DATABASE")
+	}
+	return false
+}
+
+// isAlreadyApplied reports whether the statement failed because its result already exists.
+func isAlreadyApplied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && slices.Contains(appliedErrors, mysqlErr.Number)
+}
+
+// splitStatements splits the SQL script into statements. A statement ends with the delimiter at the end of a line,
+// optionally followed by a '--' comment. The delimiter can be changed with the 'DELIMITER' command of the mysql
+// client, e.g. for triggers.
+func splitStatements(script string) []string {
+	delimiter := ";"
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fields := strings.Fields(trimmed); len(fields) == 2 && strings.EqualFold(fields[0], "DELIMITER") {
+			delimiter = fields[1]
+			continue
+		}
+		if current.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		code := trimmed
+		if i := strings.Index(code, "--"); i >= 0 {
+			code = strings.TrimSpace(code[:i])
+		}
+		if strings.HasSuffix(code, delimiter) {
+			statement := current.String()
+			statement = strings.TrimSpace(statement[:strings.LastIndex(statement, delimiter)])
+			statements = append(statements, statement)
+			current.Reset()
+		}
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
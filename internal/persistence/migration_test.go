@@ -0,0 +1,229 @@
+package persistence
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IliaW/robots-api/database"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+const createMigrationsTable = "CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(255) PRIMARY KEY, " +
+	"applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)"
+
+const countBaselineTable = "SELECT COUNT(*) FROM information_schema.tables " +
+	"WHERE table_schema = DATABASE() AND table_name = ?"
+
+// expectMigrationStart expects the migration lock and the query of the applied versions.
+func expectMigrationStart(dbMock sqlmock.Sqlmock, applied ...string) {
+	dbMock.ExpectQuery("SELECT GET_LOCK(?, ?)").
+		WithArgs("schema_migrations", 60).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+	dbMock.ExpectExec(createMigrationsTable).WillReturnResult(sqlmock.NewResult(0, 0))
+	versions := sqlmock.NewRows([]string{"version"})
+	for _, version := range applied {
+		versions.AddRow(version)
+	}
+	dbMock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(versions)
+}
+
+func expectMigrationSaved(dbMock sqlmock.Sqlmock, version string) {
+	dbMock.ExpectExec("INSERT INTO schema_migrations (version) VALUES (?)").
+		WithArgs(version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectMigrationUnlocked(dbMock sqlmock.Sqlmock) {
+	dbMock.ExpectQuery("SELECT RELEASE_LOCK(?)").
+		WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"released"}).AddRow(1))
+}
+
+func Test_Migrate_AppliesPendingMigrations(t *testing.T) {
+	migrations := fstest.MapFS{
+		"2024-11-04__init.sql":  {Data: []byte("CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n")},
+		"2024-12-01__index.sql": {Data: []byte("CREATE INDEX a_index ON a (id);\n")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	expectMigrationStart(dbMock, "2024-11-04__init")
+	dbMock.ExpectExec("CREATE INDEX a_index ON a (id)").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectMigrationSaved(dbMock, "2024-12-01__index")
+	expectMigrationUnlocked(dbMock)
+
+	err = Migrate(db, migrations, slog.Default())
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func Test_Migrate_AdoptsExistingSchema(t *testing.T) {
+	migrations := fstest.MapFS{
+		"2024-11-04__init.sql":  {Data: []byte("CREATE TABLE custom_rule (id INT);\n")},
+		"2024-12-01__index.sql": {Data: []byte("CREATE INDEX id_index ON custom_rule (id);\n")},
+	}
+	testSet := []struct {
+		name           string
+		existingTables int
+	}{
+		{
+			name:           "existing schema is adopted",
+			existingTables: 1,
+		},
+		{
+			name:           "empty database runs the baseline",
+			existingTables: 0,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(tt, err)
+			defer db.Close()
+			expectMigrationStart(dbMock)
+			dbMock.ExpectQuery(countBaselineTable).
+				WithArgs("custom_rule").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(test.existingTables))
+			if test.existingTables == 0 {
+				dbMock.ExpectExec("CREATE TABLE custom_rule (id INT)").WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			expectMigrationSaved(dbMock, "2024-11-04__init")
+			dbMock.ExpectExec("CREATE INDEX id_index ON custom_rule (id)").WillReturnResult(sqlmock.NewResult(0, 0))
+			expectMigrationSaved(dbMock, "2024-12-01__index")
+			expectMigrationUnlocked(dbMock)
+
+			err = Migrate(db, migrations, slog.Default())
+
+			assert.NoError(tt, err)
+			assert.NoError(tt, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Migrate_StatementErrors(t *testing.T) {
+	migrations := fstest.MapFS{
+		"2024-12-01__note.sql": {Data: []byte("ALTER TABLE a ADD COLUMN note INT;\nCREATE INDEX note_index ON a (note);\n")},
+	}
+	testSet := []struct {
+		name          string
+		err           error
+		expectedSaved bool
+	}{
+		{
+			name:          "duplicate column is skipped",
+			err:           &mysql.MySQLError{Number: 1060, Message: "Duplicate column name 'note'"},
+			expectedSaved: true,
+		},
+		{
+			name:          "other error stops the migration",
+			err:           &mysql.MySQLError{Number: 1146, Message: "Table 'a' doesn't exist"},
+			expectedSaved: false,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(tt, err)
+			defer db.Close()
+			expectMigrationStart(dbMock, "2024-11-04__init")
+			dbMock.ExpectExec("ALTER TABLE a ADD COLUMN note INT").WillReturnError(test.err)
+			if test.expectedSaved {
+				dbMock.ExpectExec("CREATE INDEX note_index ON a (note)").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectMigrationSaved(dbMock, "2024-12-01__note")
+			}
+			expectMigrationUnlocked(dbMock)
+
+			err = Migrate(db, migrations, slog.Default())
+
+			assert.Equal(tt, test.expectedSaved, err == nil)
+			assert.NoError(tt, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Migrate_LockedByAnotherReplica(t *testing.T) {
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	dbMock.ExpectQuery("SELECT GET_LOCK(?, ?)").
+		WithArgs("schema_migrations", 60).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(0))
+
+	err = Migrate(db, fstest.MapFS{}, slog.Default())
+
+	assert.ErrorContains(t, err, "failed to lock the migrations")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func Test_Migrate_EmbeddedMigrations(t *testing.T) {
+	// the statements are matched by the prefix, the schema setup of the init script is not run
+	prefixMatcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		if !strings.HasPrefix(actualSQL, expectedSQL) {
+			return fmt.Errorf("'%s' doesn't start with '%s'", actualSQL, expectedSQL)
+		}
+		return nil
+	})
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(prefixMatcher))
+	assert.NoError(t, err)
+	defer db.Close()
+	expectMigrationStart(dbMock)
+	dbMock.ExpectQuery(countBaselineTable).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	migrations := []struct {
+		version    string
+		statements []string
+	}{
+		{
+			version: "2024-11-04__init",
+			statements: []string{
+				"CREATE TABLE IF NOT EXISTS scrape_metadata",
+				"CREATE TABLE IF NOT EXISTS custom_rule",
+				"CREATE TABLE IF NOT EXISTS assessor_api_key",
+				"CREATE TRIGGER before_insert_assessor_api_key",
+			},
+		},
+		{version: "2026-10-16__custom_rule_note", statements: []string{"ALTER TABLE custom_rule\n    ADD COLUMN note"}},
+		{
+			version:    "2026-10-16__custom_rule_soft_delete",
+			statements: []string{"ALTER TABLE custom_rule\n    ADD COLUMN deleted_at"},
+		},
+		{
+			version:    "2026-10-16__custom_rule_source_url",
+			statements: []string{"ALTER TABLE custom_rule\n    ADD COLUMN source_url"},
+		},
+		{version: "2026-10-16__key_usage", statements: []string{"CREATE TABLE IF NOT EXISTS api_key_usage"}},
+	}
+	for _, migration := range migrations {
+		for _, statement := range migration.statements {
+			dbMock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		expectMigrationSaved(dbMock, migration.version)
+	}
+	expectMigrationUnlocked(dbMock)
+
+	err = Migrate(db, database.Migrations(), slog.Default())
+
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func Test_SplitStatements(t *testing.T) {
+	script := "-- comment\n" +
+		"CREATE TABLE a\n(\n    id INT -- the id\n);\n\n" +
+		"ALTER TABLE a ADD COLUMN b INT; -- trailing comment\n" +
+		"DELIMITER $$\n" +
+		"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW\nBEGIN\n    SET NEW.b = 1;\nEND$$\n" +
+		"DELIMITER ;\n"
+
+	assert.Equal(t, []string{
+		"CREATE TABLE a\n(\n    id INT -- the id\n)",
+		"ALTER TABLE a ADD COLUMN b INT",
+		"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW\nBEGIN\n    SET NEW.b = 1;\nEND",
+	}, splitStatements(script))
+}
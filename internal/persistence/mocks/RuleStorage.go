@@ -5,6 +5,8 @@ package mocks
 import (
 	model "github.com/IliaW/robots-api/internal/model"
 	"github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // RuleStorage is an autogenerated mock type for the RuleStorage type
@@ -90,6 +92,124 @@ func (_m *RuleStorage) GetByUrl(_a0 string) (*model.Rule, error) {
 	return r0, r1
 }
 
+// GetByUrlBulk provides a mock function with given fields: _a0
+func (_m *RuleStorage) GetByUrlBulk(_a0 string) (*model.Rule, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUrlBulk")
+	}
+
+	var r0 *model.Rule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.Rule, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.Rule); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Rule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDomains provides a mock function with given fields: _a0, _a1
+func (_m *RuleStorage) ListDomains(_a0 int, _a1 int) ([]*model.RuleDomain, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDomains")
+	}
+
+	var r0 []*model.RuleDomain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, int) ([]*model.RuleDomain, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(int, int) []*model.RuleDomain); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.RuleDomain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRules provides a mock function with given fields: _a0, _a1
+func (_m *RuleStorage) ListRules(_a0 int, _a1 int) ([]*model.Rule, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRules")
+	}
+
+	var r0 []*model.Rule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, int) ([]*model.Rule, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(int, int) []*model.Rule); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Rule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeleted provides a mock function with given fields: _a0
+func (_m *RuleStorage) PurgeDeleted(_a0 time.Time) (int64, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeleted")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: _a0
 func (_m *RuleStorage) Save(_a0 *model.Rule) (int64, error) {
 	ret := _m.Called(_a0)
@@ -118,6 +238,38 @@ func (_m *RuleStorage) Save(_a0 *model.Rule) (int64, error) {
 	return r0, r1
 }
 
+// SaveBatch provides a mock function with given fields: _a0
+func (_m *RuleStorage) SaveBatch(_a0 []*model.Rule) ([]int64, []error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveBatch")
+	}
+
+	var r0 []int64
+	var r1 []error
+	if rf, ok := ret.Get(0).(func([]*model.Rule) ([]int64, []error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func([]*model.Rule) []int64); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]*model.Rule) []error); ok {
+		r1 = rf(_a0)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]error)
+		}
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: _a0
 func (_m *RuleStorage) Update(_a0 *model.Rule) (*model.Rule, error) {
 	ret := _m.Called(_a0)
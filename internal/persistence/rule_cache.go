@@ -0,0 +1,110 @@
+package persistence
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+)
+
+// RuleCache is a bounded in-memory LRU cache of recently used custom rules keyed by the domain of the looked up url.
+// The key may differ from the domain of the rule, e.g. if the rule is found by the www variant. It keeps the custom
+// rules of hot domains available while the database is unavailable.
+// A nil *RuleCache is a disabled cache: Get always misses and Put and Delete do nothing.
+type RuleCache struct {
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+type cachedRule struct {
+	domain  string
+	rule    *model.Rule
+	expires time.Time
+}
+
+func NewRuleCache(size int, ttl time.Duration) *RuleCache {
+	return &RuleCache{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// Get returns the cached rule for the domain if it is not expired.
+func (c *RuleCache) Get(domain string) (*model.Rule, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[domain]
+	if !ok {
+		return nil, false
+	}
+	item := element.Value.(*cachedRule)
+	if c.now().After(item.expires) {
+		c.order.Remove(element)
+		delete(c.items, domain)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return item.rule, true
+}
+
+// Put saves the rule found for the domain. The least recently used rule is evicted if the cache is full.
+func (c *RuleCache) Put(domain string, rule *model.Rule) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := &cachedRule{domain: domain, rule: rule, expires: c.now().Add(c.ttl)}
+	if element, ok := c.items[domain]; ok {
+		element.Value = item
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[domain] = c.order.PushFront(item)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedRule).domain)
+	}
+}
+
+// Delete removes the rule of the domain, e.g. when the database reports that the rule doesn't exist anymore.
+func (c *RuleCache) Delete(domain string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[domain]; ok {
+		c.order.Remove(element)
+		delete(c.items, domain)
+	}
+}
+
+// Invalidate removes the cached rules replaced by a write of the rule: the rule with the same id and the rules
+// of the same domain, whatever domain they are found for.
+func (c *RuleCache) Invalidate(rule *model.Rule) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for domain, element := range c.items {
+		cached := element.Value.(*cachedRule).rule
+		if domain == rule.Domain || cached.ID == rule.ID || cached.Domain == rule.Domain {
+			c.order.Remove(element)
+			delete(c.items, domain)
+		}
+	}
+}
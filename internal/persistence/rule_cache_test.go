@@ -0,0 +1,117 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RuleCache_Evicts_LeastRecentlyUsed(t *testing.T) {
+	c := NewRuleCache(2, time.Minute)
+	c.Put("a.com", &model.Rule{ID: 1, Domain: "a.com"})
+	c.Put("b.com", &model.Rule{ID: 2, Domain: "b.com"})
+	_, _ = c.Get("a.com")
+	c.Put("c.com", &model.Rule{ID: 3, Domain: "c.com"})
+
+	_, ok := c.Get("b.com")
+	assert.False(t, ok)
+	rule, ok := c.Get("a.com")
+	assert.True(t, ok)
+	assert.Equal(t, 1, rule.ID)
+	rule, ok = c.Get("c.com")
+	assert.True(t, ok)
+	assert.Equal(t, 3, rule.ID)
+}
+
+func Test_RuleCache_Expires(t *testing.T) {
+	now := time.Date(2024, 11, 4, 10, 0, 0, 0, time.UTC)
+	c := NewRuleCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Put("a.com", &model.Rule{ID: 1, Domain: "a.com"})
+
+	now = now.Add(30 * time.Second)
+	_, ok := c.Get("a.com")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a.com")
+	assert.False(t, ok)
+}
+
+func Test_RuleCache_Delete(t *testing.T) {
+	c := NewRuleCache(2, time.Minute)
+	c.Put("a.com", &model.Rule{ID: 1, Domain: "a.com"})
+	c.Delete("a.com")
+
+	_, ok := c.Get("a.com")
+	assert.False(t, ok)
+}
+
+func Test_RuleCache_Nil(t *testing.T) {
+	var c *RuleCache
+	c.Put("a.com", &model.Rule{ID: 1, Domain: "a.com"})
+	c.Delete("a.com")
+
+	_, ok := c.Get("a.com")
+	assert.False(t, ok)
+}
+
+func Test_RuleCache_KeyedByLookupDomain(t *testing.T) {
+	c := NewRuleCache(2, time.Minute)
+	c.Put("www.a.com", &model.Rule{ID: 1, Domain: "a.com"})
+
+	rule, ok := c.Get("www.a.com")
+	assert.True(t, ok)
+	assert.Equal(t, 1, rule.ID)
+	_, ok = c.Get("a.com")
+	assert.False(t, ok)
+}
+
+func Test_RuleCache_Invalidate(t *testing.T) {
+	testSet := []struct {
+		name            string
+		rule            *model.Rule
+		expectedDomains []string
+	}{
+		{
+			name:            "rule with the same id",
+			rule:            &model.Rule{ID: 1, Domain: "c.com"},
+			expectedDomains: []string{"b.com"},
+		},
+		{
+			name:            "rule of the same domain",
+			rule:            &model.Rule{ID: 3, Domain: "a.com"},
+			expectedDomains: []string{"b.com"},
+		},
+		{
+			name:            "rule of the looked up domain",
+			rule:            &model.Rule{ID: 3, Domain: "b.com"},
+			expectedDomains: []string{"www.a.com"},
+		},
+		{
+			name:            "deleted rule by id",
+			rule:            &model.Rule{ID: 2},
+			expectedDomains: []string{"www.a.com"},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			c := NewRuleCache(2, time.Minute)
+			c.Put("www.a.com", &model.Rule{ID: 1, Domain: "a.com"})
+			c.Put("b.com", &model.Rule{ID: 2, Domain: "b.com"})
+
+			c.Invalidate(test.rule)
+
+			var domains []string
+			for _, domain := range []string{"www.a.com", "b.com"} {
+				if _, ok := c.Get(domain); ok {
+					domains = append(domains, domain)
+				}
+			}
+			assert.Equal(tt, test.expectedDomains, domains)
+			assert.Equal(tt, len(test.expectedDomains), c.order.Len())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package persistence
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PurgeDeletedRules hard-deletes the rules soft-deleted longer than the retention ago. The purge runs on every
+// interval until the context is done.
+func PurgeDeletedRules(ctx context.Context, ruleRepo RuleStorage, retention, interval time.Duration,
+	log *slog.Logger) {
+	log.Info("deleted rules purger started.", slog.Duration("retention", retention),
+		slog.Duration("interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("deleted rules purger stopped.")
+			return
+		case now := <-ticker.C:
+			purgeDeletedRules(ruleRepo, now.Add(-retention), log)
+		}
+	}
+}
+
+func purgeDeletedRules(ruleRepo RuleStorage, deletedBefore time.Time, log *slog.Logger) {
+	purged, err := ruleRepo.PurgeDeleted(deletedBefore)
+	if err != nil {
+		log.Error("failed to purge deleted rules.", slog.String("err", err.Error()))
+		return
+	}
+	log.Info("deleted rules purged.", slog.Int64("count", purged), slog.Time("deleted_before", deletedBefore))
+}
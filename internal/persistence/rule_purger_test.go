@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// deletedBeforeArg matches the purge time of the rules deleted longer than the retention ago.
+type deletedBeforeArg struct {
+	retention time.Duration
+}
+
+func (a deletedBeforeArg) Match(value driver.Value) bool {
+	deletedBefore, ok := value.(time.Time)
+	age := time.Since(deletedBefore)
+	return ok && age >= a.retention && age < a.retention+time.Minute
+}
+
+func Test_PurgeDeletedRules(t *testing.T) {
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	retention := 30 * 24 * time.Hour
+	dbMock.ExpectExec("DELETE FROM custom_rule WHERE deleted_at IS NOT NULL AND deleted_at < ?").
+		WithArgs(deletedBeforeArg{retention: retention}).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	ruleRepo := NewRuleRepository(db, nil, true, false, nil, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		PurgeDeletedRules(ctx, ruleRepo, retention, 10*time.Millisecond, slog.Default())
+		close(stopped)
+	}()
+
+	assert.Eventually(t, func() bool { return dbMock.ExpectationsWereMet() == nil }, time.Second,
+		5*time.Millisecond)
+	cancel()
+	<-stopped
+}
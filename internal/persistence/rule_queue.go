@@ -0,0 +1,189 @@
+package persistence
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/IliaW/robots-api/util"
+)
+
+var (
+	ErrQueueFull   = errors.New("write queue is full")
+	ErrQueueClosed = errors.New("write queue is closed")
+)
+
+// defaultWriteFlushInterval is used if 'persistence.write_flush_interval' is not positive.
+const defaultWriteFlushInterval = time.Second
+
+// RuleWriteQueue saves custom rules asynchronously. Rules are buffered in a channel and saved in batches
+// by a pool of workers. The state of each write can be checked by the tracking id returned from Enqueue.
+type RuleWriteQueue struct {
+	ruleRepo RuleStorage
+	cfg      *config.PersistenceConfig
+	log      *slog.Logger
+	queue    chan *queuedRule
+	statuses map[string]*model.WriteStatus
+	statusMu sync.RWMutex
+	closed   bool
+	closeMu  sync.RWMutex
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+type queuedRule struct {
+	trackingId string
+	rule       *model.Rule
+}
+
+func NewRuleWriteQueue(ruleRepo RuleStorage, cfg *config.PersistenceConfig, log *slog.Logger) *RuleWriteQueue {
+	q := &RuleWriteQueue{
+		ruleRepo: ruleRepo,
+		cfg:      cfg,
+		log:      log,
+		queue:    make(chan *queuedRule, cfg.WriteQueueSize),
+		statuses: make(map[string]*model.WriteStatus),
+		done:     make(chan struct{}),
+	}
+	for i := 0; i < max(cfg.WriteWorkers, 1); i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	go q.cleanStatuses()
+	log.Info("rule write queue started.", slog.Int("workers", max(cfg.WriteWorkers, 1)))
+
+	return q
+}
+
+// Enqueue adds the rule to the queue and returns the tracking id of the write.
+func (q *RuleWriteQueue) Enqueue(rule *model.Rule) (string, error) {
+	q.closeMu.RLock()
+	defer q.closeMu.RUnlock()
+	if q.closed {
+		return "", ErrQueueClosed
+	}
+	item := &queuedRule{trackingId: util.GenerateId(), rule: rule}
+	q.setStatus(&model.WriteStatus{TrackingID: item.trackingId, Status: model.WriteStatusPending})
+	select {
+	case q.queue <- item:
+		return item.trackingId, nil
+	default:
+		q.deleteStatus(item.trackingId)
+		return "", ErrQueueFull
+	}
+}
+
+func (q *RuleWriteQueue) Status(trackingId string) (*model.WriteStatus, bool) {
+	q.statusMu.RLock()
+	defer q.statusMu.RUnlock()
+	status, ok := q.statuses[trackingId]
+	if !ok {
+		return nil, false
+	}
+	s := *status
+	return &s, true
+}
+
+// Close stops accepting new rules and waits until all queued rules are saved.
+func (q *RuleWriteQueue) Close() {
+	q.log.Info("draining rule write queue.", slog.Int("queued", len(q.queue)))
+	q.closeMu.Lock()
+	if q.closed {
+		q.closeMu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.queue)
+	q.closeMu.Unlock()
+	q.wg.Wait()
+	close(q.done)
+	q.log.Info("rule write queue is drained.")
+}
+
+func (q *RuleWriteQueue) work() {
+	defer q.wg.Done()
+	batchSize := max(q.cfg.WriteBatchSize, 1)
+	batch := make([]*queuedRule, 0, batchSize)
+	flushInterval := q.cfg.WriteFlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultWriteFlushInterval
+	}
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case item, ok := <-q.queue:
+			if !ok {
+				q.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) >= batchSize {
+				q.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			q.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (q *RuleWriteQueue) flush(batch []*queuedRule) {
+	if len(batch) == 0 {
+		return
+	}
+	rules := make([]*model.Rule, len(batch))
+	for i, item := range batch {
+		rules[i] = item.rule
+	}
+	ids, errs := q.ruleRepo.SaveBatch(rules)
+	failed := 0
+	for i, item := range batch {
+		status := &model.WriteStatus{TrackingID: item.trackingId, Status: model.WriteStatusDone, RuleID: ids[i]}
+		if errs[i] != nil {
+			failed++
+			status.Status = model.WriteStatusFailed
+			status.Error = errs[i].Error()
+			status.RuleID = 0
+		}
+		q.setStatus(status)
+	}
+	q.log.Debug("rules batch processed.", slog.Int("count", len(batch)), slog.Int("failed", failed))
+}
+
+// cleanStatuses removes statuses of finished writes after the configured ttl.
+func (q *RuleWriteQueue) cleanStatuses() {
+	ticker := time.NewTicker(max(q.cfg.WriteStatusTtl/2, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+			q.statusMu.Lock()
+			for id, status := range q.statuses {
+				if status.Status != model.WriteStatusPending && time.Since(status.UpdatedAt) > q.cfg.WriteStatusTtl {
+					delete(q.statuses, id)
+				}
+			}
+			q.statusMu.Unlock()
+		}
+	}
+}
+
+func (q *RuleWriteQueue) setStatus(status *model.WriteStatus) {
+	status.UpdatedAt = time.Now()
+	q.statusMu.Lock()
+	defer q.statusMu.Unlock()
+	q.statuses[status.TrackingID] = status
+}
+
+func (q *RuleWriteQueue) deleteStatus(trackingId string) {
+	q.statusMu.Lock()
+	defer q.statusMu.Unlock()
+	delete(q.statuses, trackingId)
+}
@@ -0,0 +1,134 @@
+package persistence
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/IliaW/robots-api/internal/model"
+	storageMock "github.com/IliaW/robots-api/internal/persistence/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testLog = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func queueConfig(batchSize int, flushInterval time.Duration) *config.PersistenceConfig {
+	return &config.PersistenceConfig{
+		AsyncWrites:        true,
+		WriteQueueSize:     100,
+		WriteWorkers:       1,
+		WriteBatchSize:     batchSize,
+		WriteFlushInterval: flushInterval,
+		WriteStatusTtl:     time.Hour,
+	}
+}
+
+// saveBatchMock returns sequential ids and fails the rules for the 'fail.com' domain.
+func saveBatchMock(rules []*model.Rule) ([]int64, []error) {
+	ids := make([]int64, len(rules))
+	errs := make([]error, len(rules))
+	for i, rule := range rules {
+		if rule.Domain == "fail.com" {
+			errs[i] = errors.New("duplicate entry")
+			continue
+		}
+		ids[i] = int64(i + 1)
+	}
+	return ids, errs
+}
+
+func Test_RuleWriteQueue_Enqueue(t *testing.T) {
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("SaveBatch", mock.Anything).Return(saveBatchMock)
+	q := NewRuleWriteQueue(ruleRepo, queueConfig(10, 10*time.Millisecond), testLog)
+	defer q.Close()
+
+	okId, err := q.Enqueue(&model.Rule{Domain: "example.com", RobotsTxt: "User-agent: *"})
+	assert.NoError(t, err)
+	failId, err := q.Enqueue(&model.Rule{Domain: "fail.com", RobotsTxt: "User-agent: *"})
+	assert.NoError(t, err)
+
+	status, ok := q.Status(okId)
+	assert.True(t, ok)
+	assert.Equal(t, model.WriteStatusPending, status.Status)
+
+	assert.Eventually(t, func() bool {
+		status, _ = q.Status(okId)
+		return status.Status != model.WriteStatusPending
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, model.WriteStatusDone, status.Status)
+	assert.Equal(t, int64(1), status.RuleID)
+
+	status, _ = q.Status(failId)
+	assert.Equal(t, model.WriteStatusFailed, status.Status)
+	assert.Equal(t, "duplicate entry", status.Error)
+
+	_, ok = q.Status("unknown")
+	assert.False(t, ok)
+}
+
+func Test_RuleWriteQueue_DrainOnClose(t *testing.T) {
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("SaveBatch", mock.Anything).Return(saveBatchMock)
+	// the batch is never full and the flush interval is never reached, so only Close saves the rules
+	q := NewRuleWriteQueue(ruleRepo, queueConfig(100, time.Hour), testLog)
+
+	ids := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		id, err := q.Enqueue(&model.Rule{Domain: "example.com", RobotsTxt: "User-agent: *"})
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	q.Close()
+
+	for _, id := range ids {
+		status, ok := q.Status(id)
+		assert.True(t, ok)
+		assert.Equal(t, model.WriteStatusDone, status.Status)
+	}
+	ruleRepo.AssertNumberOfCalls(t, "SaveBatch", 1)
+
+	_, err := q.Enqueue(&model.Rule{Domain: "example.com", RobotsTxt: "User-agent: *"})
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func Test_RuleWriteQueue_DefaultFlushInterval(t *testing.T) {
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("SaveBatch", mock.Anything).Return(saveBatchMock)
+	// a missing flush interval must not stop the workers, the batch is flushed by the default interval
+	q := NewRuleWriteQueue(ruleRepo, queueConfig(100, 0), testLog)
+	defer q.Close()
+
+	id, err := q.Enqueue(&model.Rule{Domain: "example.com", RobotsTxt: "User-agent: *"})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		status, _ := q.Status(id)
+		return status.Status == model.WriteStatusDone
+	}, 3*defaultWriteFlushInterval, 10*time.Millisecond)
+}
+
+func Test_RuleWriteQueue_Full(t *testing.T) {
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("SaveBatch", mock.Anything).Maybe().Return(saveBatchMock)
+	cfg := queueConfig(100, time.Hour)
+	cfg.WriteQueueSize = 0
+	cfg.WriteWorkers = 0
+	q := &RuleWriteQueue{
+		ruleRepo: ruleRepo,
+		cfg:      cfg,
+		log:      testLog,
+		queue:    make(chan *queuedRule),
+		statuses: make(map[string]*model.WriteStatus),
+		done:     make(chan struct{}),
+	}
+
+	id, err := q.Enqueue(&model.Rule{Domain: "example.com", RobotsTxt: "User-agent: *"})
+	assert.ErrorIs(t, err, ErrQueueFull)
+	_, ok := q.Status(id)
+	assert.False(t, ok)
+}
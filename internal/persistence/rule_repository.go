@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/IliaW/robots-api/internal/model"
 	"github.com/IliaW/robots-api/util"
@@ -17,66 +20,142 @@ type RuleStorage interface {
 	GetByUrl(string) (*model.Rule, error)
 	GetById(string) (*model.Rule, error)
 	Save(*model.Rule) (int64, error)
+	SaveBatch([]*model.Rule) ([]int64, []error)
 	Update(*model.Rule) (*model.Rule, error)
 	Delete(string) error
+	PurgeDeleted(time.Time) (int64, error)
+	ListDomains(int, int) ([]*model.RuleDomain, error)
+	ListRules(int, int) ([]*model.Rule, error)
+	GetByUrlBulk(string) (*model.Rule, error)
 }
 
+// deleteReplacedRuleQuery removes the soft-deleted rule of the domain before a rule is saved or moved
+// to the domain, so it doesn't violate the unique domain.
+const deleteReplacedRuleQuery = "DELETE FROM custom_rule WHERE domain = ? AND deleted_at IS NOT NULL"
+
+// RuleRepository stores custom rules in the custom_rule table. If soft delete is enabled, deleted rules are only
+// marked with deleted_at and are hard-deleted later by PurgeDeleted. If tryWwwVariant is enabled, GetByUrl also
+// looks up the other www form of the domain, e.g. the rule saved for 'example.com' applies to 'www.example.com'.
 type RuleRepository struct {
-	db  *sql.DB
-	log *slog.Logger
-	mu  sync.Mutex
+	db            *sql.DB
+	getDomain     util.DomainFunc
+	softDelete    bool
+	tryWwwVariant bool
+	bulk          *BulkLimiter
+	log           *slog.Logger
+	mu            sync.Mutex
 }
 
-func NewRuleRepository(db *sql.DB, log *slog.Logger) *RuleRepository {
+func NewRuleRepository(db *sql.DB, getDomain util.DomainFunc, softDelete, tryWwwVariant bool, bulk *BulkLimiter,
+	log *slog.Logger) *RuleRepository {
 	return &RuleRepository{
-		db:  db,
-		log: log,
+		db:            db,
+		getDomain:     getDomain,
+		softDelete:    softDelete,
+		tryWwwVariant: tryWwwVariant,
+		bulk:          bulk,
+		log:           log,
 	}
 }
 
+// GetByUrl returns the rule of the url domain. If tryWwwVariant is enabled and the domain has no rule, the rule
+// of the apex or www form of the domain is returned.
 func (r *RuleRepository) GetByUrl(url string) (*model.Rule, error) {
-	domain, err := util.GetDomain(url)
+	domain, err := r.getDomain(url)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("failed to parse url. %s", err.Error()))
 	}
-	var rule model.Rule
-	row := r.db.QueryRow("SELECT id, domain, robots_txt, created_at, updated_at FROM custom_rule WHERE domain = ?",
+	rule, err := r.getByDomain(domain)
+	if errors.Is(err, ErrNotFound) && r.tryWwwVariant {
+		if variant, ok := wwwVariant(domain); ok {
+			if variantRule, variantErr := r.getByDomain(variant); !errors.Is(variantErr, ErrNotFound) {
+				return variantRule, variantErr
+			}
+		}
+	}
+	return rule, err
+}
+
+// GetByUrlBulk is GetByUrl for the bulk requests, e.g. the batch scrape check. The read holds a connection
+// of the bulk limiter, so the reads of the bulk requests can't take all the connections of the pool.
+func (r *RuleRepository) GetByUrlBulk(url string) (*model.Rule, error) {
+	defer r.bulk.acquire()()
+	return r.GetByUrl(url)
+}
+
+// wwwVariant returns the apex form of the 'www.' domain or the 'www.' form of the apex domain.
+// IP addresses have no variant.
+func wwwVariant(domain string) (string, bool) {
+	if net.ParseIP(domain) != nil {
+		return "", false
+	}
+	if apex, found := strings.CutPrefix(domain, "www."); found {
+		return apex, true
+	}
+	return "www." + domain, true
+}
+
+func (r *RuleRepository) getByDomain(domain string) (*model.Rule, error) {
+	row := r.db.QueryRow("SELECT id, domain, robots_txt, note, source_url, created_at, updated_at FROM custom_rule "+
+		"WHERE domain = ? AND deleted_at IS NULL",
 		domain)
-	err = row.Scan(&rule.ID, &rule.Domain, &rule.RobotsTxt, &rule.CreatedAt, &rule.UpdatedAt)
+	rule, err := scanRule(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New(fmt.Sprintf("rule with domain '%s' not found", domain))
+			return nil, fmt.Errorf("rule with domain '%s' %w", domain, ErrNotFound)
 		}
 		r.log.Debug("failed to get rule from database.", slog.String("err", err.Error()))
 		return nil, err
 	}
 	r.log.Debug("rule fetched from db.")
 
-	return &rule, nil
+	return rule, nil
 }
 
 func (r *RuleRepository) GetById(id string) (*model.Rule, error) {
-	var rule model.Rule
-	row := r.db.QueryRow("SELECT id, domain, robots_txt, created_at, updated_at FROM custom_rule WHERE id = ?",
+	row := r.db.QueryRow("SELECT id, domain, robots_txt, note, source_url, created_at, updated_at FROM custom_rule "+
+		"WHERE id = ? AND deleted_at IS NULL",
 		id)
-	err := row.Scan(&rule.ID, &rule.Domain, &rule.RobotsTxt, &rule.CreatedAt, &rule.UpdatedAt)
+	rule, err := scanRule(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New(fmt.Sprintf("rule with id '%s' not found", id))
+			return nil, fmt.Errorf("rule with id '%s' %w", id, ErrNotFound)
 		}
 		r.log.Debug("failed to get rule from database.", slog.String("err", err.Error()))
 		return nil, err
 	}
 	r.log.Debug("rule fetched from db.")
 
+	return rule, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRule scans the rule row. The NULL timestamps of legacy rows are mapped to the zero time.
+func scanRule(row rowScanner) (*model.Rule, error) {
+	var rule model.Rule
+	var createdAt, updatedAt sql.NullTime
+	err := row.Scan(&rule.ID, &rule.Domain, &rule.RobotsTxt, &rule.Note, &rule.SourceUrl, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rule.CreatedAt = createdAt.Time
+	rule.UpdatedAt = updatedAt.Time
+
 	return &rule, nil
 }
 
 func (r *RuleRepository) Save(rule *model.Rule) (int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	result, err := r.db.Exec("INSERT INTO custom_rule (domain, robots_txt) VALUES (?, ?)",
-		rule.Domain, rule.RobotsTxt)
+	if _, err := r.db.Exec(deleteReplacedRuleQuery, rule.Domain); err != nil {
+		return 0, err
+	}
+	result, err := r.db.Exec("INSERT INTO custom_rule (domain, robots_txt, note, source_url) VALUES (?, ?, ?, ?)",
+		rule.Domain, rule.RobotsTxt, rule.Note, rule.SourceUrl)
 	if err != nil {
 		return 0, err
 	}
@@ -85,9 +164,66 @@ func (r *RuleRepository) Save(rule *model.Rule) (int64, error) {
 	return result.LastInsertId()
 }
 
+// SaveBatch saves the rules in one transaction. The returned ids and errors have the same order as the rules.
+// A failed insert doesn't prevent other rules of the batch from being saved.
+func (r *RuleRepository) SaveBatch(rules []*model.Rule) ([]int64, []error) {
+	ids := make([]int64, len(rules))
+	errs := make([]error, len(rules))
+	failAll := func(err error) ([]int64, []error) {
+		for i := range errs {
+			ids[i] = 0
+			errs[i] = err
+		}
+		return ids, errs
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer r.bulk.acquire()()
+	tx, err := r.db.Begin()
+	if err != nil {
+		return failAll(err)
+	}
+	stmt, err := tx.Prepare("INSERT INTO custom_rule (domain, robots_txt, note, source_url) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		_ = tx.Rollback()
+		return failAll(err)
+	}
+	defer stmt.Close()
+	deleteStmt, err := tx.Prepare(deleteReplacedRuleQuery)
+	if err != nil {
+		_ = tx.Rollback()
+		return failAll(err)
+	}
+	defer deleteStmt.Close()
+	for i, rule := range rules {
+		if _, err = deleteStmt.Exec(rule.Domain); err != nil {
+			errs[i] = err
+			continue
+		}
+		result, err := stmt.Exec(rule.Domain, rule.RobotsTxt, rule.Note, rule.SourceUrl)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		ids[i], errs[i] = result.LastInsertId()
+	}
+	if err = tx.Commit(); err != nil {
+		return failAll(err)
+	}
+	r.log.Debug("rules batch saved to db.", slog.Int("count", len(rules)))
+
+	return ids, errs
+}
+
 func (r *RuleRepository) Update(rule *model.Rule) (*model.Rule, error) {
-	_, err := r.db.Exec("UPDATE custom_rule SET domain = ?, robots_txt = ? WHERE id = ?",
-		rule.Domain, rule.RobotsTxt, rule.ID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.db.Exec(deleteReplacedRuleQuery, rule.Domain); err != nil {
+		return nil, err
+	}
+	_, err := r.db.Exec("UPDATE custom_rule SET domain = ?, robots_txt = ?, note = ? "+
+		"WHERE id = ? AND deleted_at IS NULL",
+		rule.Domain, rule.RobotsTxt, rule.Note, rule.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -97,11 +233,73 @@ func (r *RuleRepository) Update(rule *model.Rule) (*model.Rule, error) {
 }
 
 func (r *RuleRepository) Delete(ruleId string) error {
-	_, err := r.db.Exec("DELETE FROM custom_rule WHERE id = ?", ruleId)
+	query := "DELETE FROM custom_rule WHERE id = ?"
+	if r.softDelete {
+		query = "UPDATE custom_rule SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	}
+	_, err := r.db.Exec(query, ruleId)
 	if err != nil {
 		return err
 	}
-	r.log.Debug("rule deleted from db.")
+	r.log.Debug("rule deleted from db.", slog.Bool("soft", r.softDelete))
 
 	return nil
 }
+
+// ListDomains returns the ids and domains of the rules ordered by the domain, without the rule content.
+func (r *RuleRepository) ListDomains(limit, offset int) ([]*model.RuleDomain, error) {
+	rows, err := r.db.Query("SELECT id, domain FROM custom_rule WHERE deleted_at IS NULL "+
+		"ORDER BY domain LIMIT ? OFFSET ?",
+		limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := make([]*model.RuleDomain, 0)
+	for rows.Next() {
+		var domain model.RuleDomain
+		if err = rows.Scan(&domain.ID, &domain.Domain); err != nil {
+			return nil, err
+		}
+		domains = append(domains, &domain)
+	}
+
+	return domains, rows.Err()
+}
+
+// ListRules returns up to limit rules with the id greater than afterId ordered by the id. The keyset pagination
+// keeps the pages of a large table cheap: pass the id of the last rule of the page to get the next one.
+func (r *RuleRepository) ListRules(afterId, limit int) ([]*model.Rule, error) {
+	// the rules are listed only by the bulk requests, e.g. the validation of all rules
+	defer r.bulk.acquire()()
+	rows, err := r.db.Query("SELECT id, domain, robots_txt, note, source_url, created_at, updated_at FROM custom_rule "+
+		"WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?",
+		afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]*model.Rule, 0)
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// PurgeDeleted hard-deletes the rules soft-deleted before the given time and returns the number of purged rules.
+func (r *RuleRepository) PurgeDeleted(deletedBefore time.Time) (int64, error) {
+	defer r.bulk.acquire()()
+	result, err := r.db.Exec("DELETE FROM custom_rule WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,251 @@
+package persistence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	u "net/url"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RuleRepository_GetById_Timestamps(t *testing.T) {
+	createdAt := time.Date(2024, 10, 5, 10, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 10, 6, 10, 0, 0, 0, time.UTC)
+	sourceUrl := "https://example.com/robots.txt"
+	testSet := []struct {
+		name         string
+		row          []driver.Value
+		expectedRule *model.Rule
+	}{
+		{
+			name: "timestamps",
+			row:  []driver.Value{int64(1), "example.com", "User-agent: *", nil, nil, createdAt, updatedAt},
+			expectedRule: &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *",
+				CreatedAt: createdAt, UpdatedAt: updatedAt},
+		},
+		{
+			name: "source url of an imported rule",
+			row: []driver.Value{int64(1), "example.com", "User-agent: *", nil, "https://example.com/robots.txt",
+				createdAt, updatedAt},
+			expectedRule: &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *",
+				SourceUrl: &sourceUrl, CreatedAt: createdAt, UpdatedAt: updatedAt},
+		},
+		{
+			name:         "null timestamps of a legacy row",
+			row:          []driver.Value{int64(1), "example.com", "User-agent: *", nil, nil, nil, nil},
+			expectedRule: &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *"},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			fake := &fakeDb{query: func(string) [][]driver.Value { return [][]driver.Value{test.row} }}
+			db := sql.OpenDB(fake)
+			defer db.Close()
+
+			rule, err := NewRuleRepository(db, nil, false, false, nil, slog.Default()).GetById("1")
+
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expectedRule, rule)
+		})
+	}
+}
+
+func Test_RuleRepository_GetByUrl_WwwVariant(t *testing.T) {
+	// the host is used as is, as for the legacy rules saved before the domains were normalized
+	hostDomain := func(url string) (string, error) {
+		parsedUrl, err := u.Parse(url)
+		if err != nil {
+			return "", err
+		}
+		return parsedUrl.Hostname(), nil
+	}
+	testSet := []struct {
+		name            string
+		url             string
+		savedDomain     string
+		tryWwwVariant   bool
+		expectedQueried []any
+		expectedFound   bool
+	}{
+		{
+			name:            "apex saved, www requested",
+			url:             "https://www.example.com/page",
+			savedDomain:     "example.com",
+			tryWwwVariant:   true,
+			expectedQueried: []any{"www.example.com", "example.com"},
+			expectedFound:   true,
+		},
+		{
+			name:            "www saved, apex requested",
+			url:             "https://example.com/page",
+			savedDomain:     "www.example.com",
+			tryWwwVariant:   true,
+			expectedQueried: []any{"example.com", "www.example.com"},
+			expectedFound:   true,
+		},
+		{
+			name:            "exact domain is tried first",
+			url:             "https://example.com/page",
+			savedDomain:     "example.com",
+			tryWwwVariant:   true,
+			expectedQueried: []any{"example.com"},
+			expectedFound:   true,
+		},
+		{
+			name:            "variant is not tried if disabled",
+			url:             "https://example.com/page",
+			savedDomain:     "www.example.com",
+			tryWwwVariant:   false,
+			expectedQueried: []any{"example.com"},
+			expectedFound:   false,
+		},
+		{
+			name:            "no rule for both forms",
+			url:             "https://example.com/page",
+			savedDomain:     "example.org",
+			tryWwwVariant:   true,
+			expectedQueried: []any{"example.com", "www.example.com"},
+			expectedFound:   false,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			fake := &fakeDb{}
+			fake.query = func(string) [][]driver.Value {
+				fake.mu.Lock()
+				defer fake.mu.Unlock()
+				if fake.queried[len(fake.queried)-1].args[0] != test.savedDomain {
+					return nil
+				}
+				return [][]driver.Value{{int64(1), test.savedDomain, "User-agent: *", nil, nil, nil, nil}}
+			}
+			db := sql.OpenDB(fake)
+			defer db.Close()
+
+			rule, err := NewRuleRepository(db, hostDomain, false, test.tryWwwVariant, nil, slog.Default()).
+				GetByUrl(test.url)
+
+			queried := make([]any, 0, len(fake.queried))
+			for _, q := range fake.queried {
+				queried = append(queried, q.args[0])
+			}
+			assert.Equal(tt, test.expectedQueried, queried)
+			if test.expectedFound {
+				assert.NoError(tt, err)
+				assert.Equal(tt, test.savedDomain, rule.Domain)
+			} else {
+				assert.ErrorIs(tt, err, ErrNotFound)
+				assert.Nil(tt, rule)
+			}
+		})
+	}
+}
+
+func Test_RuleRepository_ListDomains(t *testing.T) {
+	fake := &fakeDb{query: func(string) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(2), "example.com"},
+			{int64(1), "example.org"},
+		}
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	domains, err := NewRuleRepository(db, nil, false, false, nil, slog.Default()).ListDomains(10, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.RuleDomain{{ID: 2, Domain: "example.com"}, {ID: 1, Domain: "example.org"}}, domains)
+	assert.Equal(t, []fakeExec{{
+		query: "SELECT id, domain FROM custom_rule WHERE deleted_at IS NULL ORDER BY domain LIMIT ? OFFSET ?",
+		args:  []any{int64(10), int64(20)},
+	}}, fake.queried)
+}
+
+func Test_RuleRepository_ListRules(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake := &fakeDb{query: func(string) [][]driver.Value {
+		return [][]driver.Value{
+			{int64(3), "example.com", "User-agent: *", nil, nil, createdAt, createdAt},
+			{int64(7), "example.org", "", nil, nil, nil, nil},
+		}
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	rules, err := NewRuleRepository(db, nil, false, false, nil, slog.Default()).ListRules(2, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Rule{
+		{ID: 3, Domain: "example.com", RobotsTxt: "User-agent: *", CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: 7, Domain: "example.org"},
+	}, rules)
+	assert.Equal(t, []fakeExec{{
+		query: "SELECT id, domain, robots_txt, note, source_url, created_at, updated_at FROM custom_rule " +
+			"WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?",
+		args: []any{int64(2), int64(100)},
+	}}, fake.queried)
+}
+
+func Test_RuleRepository_Update(t *testing.T) {
+	db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+	rule := &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /"}
+	// the soft-deleted rule of the new domain is removed, so the update doesn't violate the unique domain
+	dbMock.ExpectExec("DELETE FROM custom_rule WHERE domain = ? AND deleted_at IS NOT NULL").
+		WithArgs("example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec("UPDATE custom_rule SET domain = ?, robots_txt = ?, note = ? "+
+		"WHERE id = ? AND deleted_at IS NULL").
+		WithArgs("example.com", "User-agent: *\nDisallow: /", nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery("SELECT id, domain, robots_txt, note, source_url, created_at, updated_at FROM custom_rule " +
+		"WHERE id = ? AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(
+			[]string{"id", "domain", "robots_txt", "note", "source_url", "created_at", "updated_at"}).
+			AddRow(1, "example.com", "User-agent: *\nDisallow: /", nil, nil, nil, nil))
+
+	updated, err := NewRuleRepository(db, nil, true, false, nil, slog.Default()).Update(rule)
+
+	assert.NoError(t, err)
+	assert.Equal(t, rule, updated)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func Test_RuleRepository_Delete(t *testing.T) {
+	testSet := []struct {
+		name          string
+		softDelete    bool
+		expectedQuery string
+	}{
+		{
+			name:          "hard delete",
+			softDelete:    false,
+			expectedQuery: "DELETE FROM custom_rule WHERE id = ?",
+		},
+		{
+			name:          "soft delete",
+			softDelete:    true,
+			expectedQuery: "UPDATE custom_rule SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL",
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			db, dbMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(tt, err)
+			defer db.Close()
+			dbMock.ExpectExec(test.expectedQuery).WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+			err = NewRuleRepository(db, nil, test.softDelete, false, nil, slog.Default()).Delete("1")
+
+			assert.NoError(tt, err)
+			assert.NoError(tt, dbMock.ExpectationsWereMet())
+		})
+	}
+}
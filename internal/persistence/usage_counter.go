@@ -0,0 +1,124 @@
+package persistence
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+)
+
+const usageCounterShards = 16
+
+// UsageCounter counts the calls of every API key in memory and periodically adds them to the storage.
+// The counters are sharded by the key, so concurrent requests with different keys rarely wait for each other.
+type UsageCounter struct {
+	usageRepo UsageStorage
+	shards    [usageCounterShards]usageShard
+	log       *slog.Logger
+	now       func() time.Time
+	flushMu   sync.Mutex
+}
+
+type usageKey struct {
+	apiKey string
+	day    string
+}
+
+type usageShard struct {
+	mu    sync.Mutex
+	calls map[usageKey]int64
+}
+
+func NewUsageCounter(usageRepo UsageStorage, log *slog.Logger) *UsageCounter {
+	return &UsageCounter{
+		usageRepo: usageRepo,
+		log:       log,
+		now:       time.Now,
+	}
+}
+
+// Increment counts one call of the API key in the current UTC day. The key is the sha256 hash of the API key.
+func (c *UsageCounter) Increment(apiKey string) {
+	c.add(usageKey{apiKey: apiKey, day: c.now().UTC().Format(time.DateOnly)}, 1)
+}
+
+func (c *UsageCounter) add(key usageKey, count int64) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key.apiKey))
+	shard := &c.shards[hash.Sum32()%usageCounterShards]
+	shard.mu.Lock()
+	if shard.calls == nil {
+		shard.calls = make(map[usageKey]int64)
+	}
+	shard.calls[key] += count
+	shard.mu.Unlock()
+}
+
+// Flush adds the counted calls to the storage. If the storage fails, the calls are kept for the next flush.
+func (c *UsageCounter) Flush() error {
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	calls := make(map[usageKey]int64)
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mu.Lock()
+		for key, count := range shard.calls {
+			calls[key] += count
+		}
+		shard.calls = nil
+		shard.mu.Unlock()
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	if err := c.usageRepo.AddUsage(usageBuckets(calls)); err != nil {
+		for key, count := range calls {
+			c.add(key, count)
+		}
+		return err
+	}
+	return nil
+}
+
+// Run flushes the counted calls on every interval until the context is done.
+func (c *UsageCounter) Run(ctx context.Context, interval time.Duration) {
+	c.log.Info("api key usage flusher started.", slog.Duration("interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.log.Info("api key usage flusher stopped.")
+			return
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				c.log.Error("failed to flush api key usage.", slog.String("err", err.Error()))
+			}
+		}
+	}
+}
+
+// Close flushes the calls counted since the last flush.
+func (c *UsageCounter) Close() error {
+	return c.Flush()
+}
+
+// usageBuckets returns the calls ordered by the day and the key, so concurrent upserts lock the rows
+// in the same order.
+func usageBuckets(calls map[usageKey]int64) []*model.UsageBucket {
+	buckets := make([]*model.UsageBucket, 0, len(calls))
+	for key, count := range calls {
+		buckets = append(buckets, &model.UsageBucket{ApiKey: key.apiKey, Day: key.day, Calls: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Day != buckets[j].Day {
+			return buckets[i].Day < buckets[j].Day
+		}
+		return buckets[i].ApiKey < buckets[j].ApiKey
+	})
+	return buckets
+}
@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// usageStorageStub records the added calls and fails while err is set.
+type usageStorageStub struct {
+	added [][]*model.UsageBucket
+	err   error
+}
+
+func (s *usageStorageStub) AddUsage(buckets []*model.UsageBucket) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.added = append(s.added, buckets)
+	return nil
+}
+
+func (s *usageStorageStub) GetUsage(time.Time, time.Time) ([]*model.ApiKeyUsage, error) {
+	return nil, nil
+}
+
+func Test_UsageCounter(t *testing.T) {
+	storage := &usageStorageStub{}
+	counter := NewUsageCounter(storage, slog.Default())
+	now := time.Date(2024, 10, 5, 23, 59, 0, 0, time.UTC)
+	counter.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter.Increment("key-a")
+			if i%4 == 0 {
+				counter.Increment("key-b")
+			}
+		}()
+	}
+	wg.Wait()
+	now = now.Add(2 * time.Minute)
+	counter.Increment("key-a")
+
+	assert.NoError(t, counter.Flush())
+	assert.Equal(t, [][]*model.UsageBucket{{
+		{ApiKey: "key-a", Day: "2024-10-05", Calls: 100},
+		{ApiKey: "key-b", Day: "2024-10-05", Calls: 25},
+		{ApiKey: "key-a", Day: "2024-10-06", Calls: 1},
+	}}, storage.added)
+
+	// nothing is added without new calls
+	assert.NoError(t, counter.Flush())
+	assert.Len(t, storage.added, 1)
+}
+
+func Test_UsageCounter_FailedFlush(t *testing.T) {
+	storage := &usageStorageStub{err: errors.New("connection refused")}
+	counter := NewUsageCounter(storage, slog.Default())
+	counter.now = func() time.Time { return time.Date(2024, 10, 5, 10, 0, 0, 0, time.UTC) }
+	counter.Increment("key-a")
+
+	assert.EqualError(t, counter.Flush(), "connection refused")
+
+	// the calls are kept for the next flush
+	storage.err = nil
+	counter.Increment("key-a")
+	assert.NoError(t, counter.Close())
+	assert.Equal(t, [][]*model.UsageBucket{{{ApiKey: "key-a", Day: "2024-10-05", Calls: 2}}}, storage.added)
+}
@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.50.0 --name UsageStorage
+type UsageStorage interface {
+	AddUsage([]*model.UsageBucket) error
+	GetUsage(time.Time, time.Time) ([]*model.ApiKeyUsage, error)
+}
+
+// UsageRepository stores the API key usage in the api_key_usage table with one row per key and UTC day.
+type UsageRepository struct {
+	db   *sql.DB
+	bulk *BulkLimiter
+	log  *slog.Logger
+}
+
+func NewUsageRepository(db *sql.DB, bulk *BulkLimiter, log *slog.Logger) *UsageRepository {
+	return &UsageRepository{
+		db:   db,
+		bulk: bulk,
+		log:  log,
+	}
+}
+
+// AddUsage adds the calls to the daily buckets in one statement, so the flush is saved completely or not at all.
+func (r *UsageRepository) AddUsage(buckets []*model.UsageBucket) error {
+	if len(buckets) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(buckets))
+	args := make([]any, 0, len(buckets)*3)
+	for _, bucket := range buckets {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, bucket.ApiKey, bucket.Day, bucket.Calls)
+	}
+	defer r.bulk.acquire()()
+	_, err := r.db.Exec("INSERT INTO api_key_usage (api_key, day, calls) VALUES "+strings.Join(values, ", ")+
+		" ON DUPLICATE KEY UPDATE calls = calls + VALUES(calls)", args...)
+	if err != nil {
+		return err
+	}
+	r.log.Debug("api key usage saved.", slog.Int("buckets", len(buckets)))
+
+	return nil
+}
+
+// GetUsage returns the number of calls of every API key between the days, both inclusive.
+func (r *UsageRepository) GetUsage(from, to time.Time) ([]*model.ApiKeyUsage, error) {
+	rows, err := r.db.Query("SELECT u.api_key, COALESCE(k.email, ''), SUM(u.calls) FROM api_key_usage u "+
+		"LEFT JOIN assessor_api_key k ON k.api_key = u.api_key WHERE u.day BETWEEN ? AND ? "+
+		"GROUP BY u.api_key, k.email ORDER BY u.api_key",
+		from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make([]*model.ApiKeyUsage, 0)
+	for rows.Next() {
+		var keyUsage model.ApiKeyUsage
+		if err = rows.Scan(&keyUsage.ApiKey, &keyUsage.Email, &keyUsage.Calls); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &keyUsage)
+	}
+
+	return usage, rows.Err()
+}
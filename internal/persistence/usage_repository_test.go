@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UsageRepository_AddUsage(t *testing.T) {
+	fake := &fakeDb{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	err := NewUsageRepository(db, nil, slog.Default()).AddUsage([]*model.UsageBucket{
+		{ApiKey: "key-a", Day: "2024-10-05", Calls: 2},
+		{ApiKey: "key-b", Day: "2024-10-05", Calls: 3},
+		{ApiKey: "key-a", Day: "2024-10-06", Calls: 1},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []fakeExec{{
+		query: "INSERT INTO api_key_usage (api_key, day, calls) VALUES (?, ?, ?), (?, ?, ?), (?, ?, ?) " +
+			"ON DUPLICATE KEY UPDATE calls = calls + VALUES(calls)",
+		args: []any{"key-a", "2024-10-05", int64(2), "key-b", "2024-10-05", int64(3),
+			"key-a", "2024-10-06", int64(1)},
+	}}, fake.execs)
+}
+
+func Test_UsageRepository_GetUsage(t *testing.T) {
+	fake := &fakeDb{query: func(string) [][]driver.Value {
+		return [][]driver.Value{
+			{"key-a", "user@mail.com", int64(42)},
+			{"key-b", "", int64(7)},
+		}
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	usage, err := NewUsageRepository(db, nil, slog.Default()).GetUsage(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.ApiKeyUsage{
+		{ApiKey: "key-a", Email: "user@mail.com", Calls: 42},
+		{ApiKey: "key-b", Email: "", Calls: 7},
+	}, usage)
+	assert.Equal(t, []fakeExec{{
+		query: "SELECT u.api_key, COALESCE(k.email, ''), SUM(u.calls) FROM api_key_usage u " +
+			"LEFT JOIN assessor_api_key k ON k.api_key = u.api_key WHERE u.day BETWEEN ? AND ? " +
+			"GROUP BY u.api_key, k.email ORDER BY u.api_key",
+		args: []any{"2024-10-01", "2024-10-31"},
+	}}, fake.queried)
+}
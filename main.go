@@ -6,27 +6,37 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/IliaW/robots-api/config"
+	"github.com/IliaW/robots-api/database"
 	docs "github.com/IliaW/robots-api/docs"
 	"github.com/IliaW/robots-api/handler"
 	cacheClient "github.com/IliaW/robots-api/internal/cache"
+	"github.com/IliaW/robots-api/internal/httpclient"
+	"github.com/IliaW/robots-api/internal/logging"
+	"github.com/IliaW/robots-api/internal/metrics"
 	"github.com/IliaW/robots-api/internal/persistence"
+	"github.com/IliaW/robots-api/util"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 	"github.com/go-sql-driver/mysql"
 	"github.com/lmittmann/tint"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	stats "github.com/semihalev/gin-stats"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -38,23 +48,63 @@ var (
 	cache      cacheClient.CachedClient
 	db         *sql.DB
 	ruleRepo   persistence.RuleStorage
+	ruleQueue  *persistence.RuleWriteQueue
+	usageRepo  persistence.UsageStorage
+	usage      *persistence.UsageCounter
 	httpClient *http.Client
+	closers    = &closerRegistry{timeout: closeTimeout}
 )
 
+// closeTimeout is the time every registered component has to flush and close on shutdown.
+const closeTimeout = 5 * time.Second
+
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name X-API-Key
 func main() {
+	migrate := flag.Bool("migrate", false, "apply pending database migrations before serving")
+	flag.Parse()
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	cfg = config.MustLoad()
 	log = setupLogger()
 	db = setupDatabase()
-	defer closeDatabase()
-	ruleRepo = persistence.NewRuleRepository(db, log)
-	cache = cacheClient.NewMemcachedClient(cfg.CacheSettings, log)
-	defer cache.Close()
+	closers.register("database", db.Close)
+	if *migrate {
+		applyMigrations()
+	}
+	getDomain := util.NewDomainFunc(cfg.RobotsSettings.RegistrableDomainKey, cfg.RobotsSettings.StripWww)
+	bulk := persistence.NewBulkLimiter(cfg.DbSettings.MaxBulkConns)
+	ruleRepo = persistence.NewRuleRepository(db, getDomain, cfg.PersistenceSettings.SoftDelete,
+		cfg.PersistenceSettings.TryWwwVariant, bulk, log)
+	if cfg.PersistenceSettings.SoftDelete {
+		go persistence.PurgeDeletedRules(ctx, ruleRepo, cfg.PersistenceSettings.SoftDeleteRetention,
+			cfg.PersistenceSettings.PurgeInterval, log)
+	}
+	if cfg.PersistenceSettings.AsyncWrites {
+		ruleQueue = persistence.NewRuleWriteQueue(ruleRepo, cfg.PersistenceSettings, log)
+		closers.register("rule write queue", func() error {
+			ruleQueue.Close()
+			return nil
+		})
+	}
+	usageRepo = persistence.NewUsageRepository(db, bulk, log)
+	if cfg.PersistenceSettings.UsageAccounting {
+		usage = persistence.NewUsageCounter(usageRepo, log)
+		go usage.Run(ctx, cfg.PersistenceSettings.UsageFlushInterval)
+		closers.register("api key usage", usage.Close)
+	}
+	memcached, err := cacheClient.NewMemcachedClient(cfg.CacheSettings, getDomain, log)
+	if err != nil {
+		log.Error("failed to connect to memcached.", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	cache = memcached
+	closers.register("cache", func() error {
+		cache.Close()
+		return nil
+	})
 	httpClient = setupHttpClient()
 	log.Info("starting application on port "+cfg.Port, slog.String("env", cfg.Env))
 
@@ -65,7 +115,8 @@ func main() {
 	}
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
+		// ErrServerClosed is returned after Shutdown, the components are closed by the main goroutine
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Error("listen:", slog.Any("err", err))
 			os.Exit(1)
 		}
@@ -75,47 +126,164 @@ func main() {
 	log.Info("stopping server...")
 	ctxT, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	err := srv.Shutdown(ctxT)
+	err = srv.Shutdown(ctxT)
 	if errors.Is(err, context.DeadlineExceeded) {
 		log.Error("shutdown timeout exceeded")
 	}
 	log.Info("server stopped.")
+	// the async components are flushed after the http server is drained, so no new work is accepted
+	if err = closers.closeAll(log); err != nil {
+		log.Error("failed to close components.", slog.String("err", err.Error()))
+	}
+}
+
+// closerRegistry closes the registered components on shutdown in the reverse order of the registration,
+// so a component is closed before the components it depends on (e.g. the rule write queue before the database).
+type closerRegistry struct {
+	closers []namedCloser
+	timeout time.Duration
+}
+
+type namedCloser struct {
+	name  string
+	close func() error
+}
+
+func (r *closerRegistry) register(name string, close func() error) {
+	r.closers = append(r.closers, namedCloser{name: name, close: close})
+}
+
+// closeAll closes every component with its own timeout. A component that fails or doesn't finish in time
+// is logged and doesn't stop the rest from closing.
+func (r *closerRegistry) closeAll(log *slog.Logger) error {
+	var errs []error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		closer := r.closers[i]
+		log.Info("closing component.", slog.String("name", closer.name))
+		if err := closeWithTimeout(closer.close, r.timeout); err != nil {
+			log.Error("failed to close component.", slog.String("name", closer.name),
+				slog.String("err", err.Error()))
+			errs = append(errs, fmt.Errorf("%s: %w", closer.name, err))
+		}
+	}
+	r.closers = nil
+	return errors.Join(errs...)
+}
+
+func closeWithTimeout(close func() error, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() { done <- close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("close timeout of %s exceeded", timeout)
+	}
 }
 
 func httpServer() *gin.Engine {
 	setupGinMod()
 	r := gin.New()
 	r.UseH2C = true
-	r.Use(gin.Recovery())
+	r.Use(recovery())
 	r.Use(setCORS())
-	r.Use(limitBodySize())
+	basePath := strings.TrimSuffix(cfg.BasePath, "/")
+	if cfg.ServerSettings != nil && cfg.ServerSettings.MaxConcurrentRequests > 0 {
+		r.Use(limitConcurrency(cfg.ServerSettings.MaxConcurrentRequests, basePath+"/ping", basePath+"/metrics"))
+	}
+	if cfg.ServerSettings != nil && cfg.ServerSettings.RequireClientUserAgent {
+		r.Use(requireClientUserAgent(basePath+"/ping", basePath+"/metrics"))
+	}
+	r.Use(limitBodySize(cfg.MaxBodySize * 1024 * 1024))
 	r.Use(stats.RequestStats())
-	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/ping", "/pprof", "/swagger", "/stats"}}))
-	r.GET("/ping", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "pong"}) })
-	r.GET("/stats", func(c *gin.Context) { c.JSON(http.StatusOK, stats.Report()) })
+	r.Use(normalizeQuery(cfg.RobotsSettings.AssumeScheme))
+	r.Use(redactUrlUserinfo())
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{basePath + "/ping", basePath + "/pprof",
+		basePath + "/swagger", basePath + "/stats", basePath + "/metrics"}}))
+	// the routes are served under the base path, so the service can be deployed behind a path-based reverse proxy
+	base := r.Group(basePath)
+	base.GET("/ping", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "pong"}) })
+	base.GET("/stats", func(c *gin.Context) { c.JSON(http.StatusOK, stats.Report()) })
+	base.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	if cfg.PprofEnabled {
-		pprof.Register(r, "/pprof")
+		pprof.Register(base, "/pprof")
 	}
 
-	robotsHandler := handler.NewRobotsHandler(cache, ruleRepo, httpClient)
-
-	scrapeAllowed := r.Group(cfg.RobotsUrlPath)
-	scrapeAllowed.GET("/scrape-allowed", robotsHandler.GetAllowedScrape)
-
-	customRule := r.Group(cfg.RobotsUrlPath)
+	robotsHandler := handler.NewRobotsHandler(cfg, cache, ruleRepo, ruleQueue, httpClient)
+	// the global 'max_body_size' applies to the bulk requests. The requests without a body reject any body,
+	// and the requests with robots.txt content in the body are limited by the robots.txt size
+	noBody := limitBodySize(0)
+	robotsTxtBody := limitBodySize(maxRobotsTxtBodySize())
+
+	scrapeAllowed := base.Group(cfg.RobotsUrlPath, noBody)
+	scrapeAllowed.GET("/scrape-allowed", allowQuery("url", "user_agent", "explain"),
+		apiKeyCheckForHeader("X-Robots-Override"), robotsHandler.GetAllowedScrape)
+	scrapeAllowed.GET("/robots-meta", allowQuery("url", "user_agent"), robotsHandler.GetRobotsMeta)
+	scrapeAllowed.GET("/matched-group", allowQuery("url", "user_agent"), robotsHandler.GetMatchedGroup)
+	scrapeAllowed.GET("/sitemaps", allowQuery("url"), robotsHandler.GetSitemaps)
+	scrapeAllowed.GET("/effective-robots", allowQuery("url"), robotsHandler.GetEffectiveRobots)
+	scrapeAllowed.GET("/sitemap-allowed", allowQuery("url", "user_agent"), robotsHandler.GetAllowedSitemap)
+	if cfg.RobotsSettings.PageCheckEnabled {
+		scrapeAllowed.GET("/page-allowed", allowQuery("url", "user_agent"), robotsHandler.GetAllowedPage)
+	}
+	inlineScrapeAllowed := base.Group(cfg.RobotsUrlPath, robotsTxtBody)
+	inlineScrapeAllowed.POST("/scrape-allowed", allowQuery("url", "user_agent", "explain"),
+		robotsHandler.EvaluateAllowedScrape)
+	// the bulk requests make the service fetch robots.txt of many origins, so they require the api key
+	bulkScrapeAllowed := base.Group(cfg.RobotsUrlPath)
+	bulkScrapeAllowed.Use(apiKeyCheck())
+	bulkScrapeAllowed.POST("/scrape-allowed/paths", allowQuery(), robotsHandler.GetAllowedPaths)
+	bulkScrapeAllowed.POST("/scrape-allowed/batch", allowQuery(), robotsHandler.GetAllowedBatch)
+
+	customRule := base.Group(cfg.RobotsUrlPath, noBody)
 	customRule.Use(apiKeyCheck())
-	customRule.GET("/custom-rule", robotsHandler.GetCustomRule)
-	customRule.POST("/custom-rule", robotsHandler.CreateCustomRule)
-	customRule.PUT("/custom-rule", robotsHandler.UpdateCustomRule)
-	customRule.DELETE("/custom-rule", robotsHandler.DeleteCustomRule)
+	customRule.GET("/custom-rule", allowQuery("id", "url", "on_missing", "include_freshness"),
+		robotsHandler.GetCustomRule)
+	customRule.GET("/custom-rule/status", allowQuery("id"), robotsHandler.GetCustomRuleStatus)
+	customRule.GET("/custom-rule/domains", allowQuery("limit", "offset"), robotsHandler.GetCustomRuleDomains)
+	customRule.GET("/custom-rule/diff", allowQuery("url"), robotsHandler.GetCustomRuleDiff)
+	customRule.GET("/custom-rule/validate-all", allowQuery(), robotsHandler.ValidateAllCustomRules)
+	customRule.POST("/custom-rule/from-origin", allowQuery("url", "note"), robotsHandler.CreateCustomRuleFromOrigin)
+	customRule.DELETE("/custom-rule", allowQuery("id"), robotsHandler.DeleteCustomRule)
+
+	customRuleWrite := base.Group(cfg.RobotsUrlPath, robotsTxtBody)
+	customRuleWrite.Use(apiKeyCheck())
+	customRuleWrite.POST("/custom-rule", allowQuery("url", "note"), robotsHandler.CreateCustomRule)
+	customRuleWrite.PUT("/custom-rule", allowQuery("id", "url", "note"), robotsHandler.UpdateCustomRule)
+
+	cacheAdmin := base.Group(cfg.RobotsUrlPath, noBody)
+	cacheAdmin.Use(apiKeyCheck())
+	cacheAdmin.GET("/cache/servers", allowQuery(), robotsHandler.GetCacheServers)
+	cacheAdmin.POST("/cache/refresh", allowQuery("url"), robotsHandler.RefreshCache)
+
+	bulkCacheAdmin := base.Group(cfg.RobotsUrlPath)
+	bulkCacheAdmin.Use(apiKeyCheck())
+	bulkCacheAdmin.POST("/cache/invalidate", allowQuery(), robotsHandler.InvalidateCache)
+
+	audit := base.Group(cfg.RobotsUrlPath, noBody)
+	audit.Use(apiKeyCheck())
+	audit.POST("/audit", allowQuery("url"), robotsHandler.AuditRobotsTxt)
+
+	configAdmin := base.Group(cfg.RobotsUrlPath, noBody)
+	configAdmin.Use(apiKeyCheck())
+	configAdmin.GET("/config/effective", allowQuery(), robotsHandler.GetEffectiveConfig)
+	configAdmin.GET("/config/sources", allowQuery(), robotsHandler.GetConfigSources)
+
+	usageAdmin := base.Group(cfg.RobotsUrlPath, noBody)
+	usageAdmin.Use(apiKeyCheck())
+	usageAdmin.GET("/usage", allowQuery("from", "to"), handler.NewUsageHandler(usageRepo).GetUsage)
+
+	debugAdmin := base.Group(cfg.RobotsUrlPath, noBody)
+	debugAdmin.Use(apiKeyCheck())
+	debugAdmin.GET("/debug/stats", allowQuery(), handler.NewDebugHandler(db).GetDebugStats)
 
 	docs.SwaggerInfo.Title = fmt.Sprintf("Robots.txt API (%s)", cfg.ServiceName)
 	docs.SwaggerInfo.Description = "This is a simple API to control scrape permissions and create custom rules for specific domains."
 	docs.SwaggerInfo.Version = cfg.Version
-	docs.SwaggerInfo.BasePath = cfg.RobotsUrlPath
+	docs.SwaggerInfo.BasePath = scrapeAllowed.BasePath()
 	docs.SwaggerInfo.Schemes = []string{"http", "https"}
 
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	base.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
 	r.NoRoute(func(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusNotFound,
@@ -132,18 +300,115 @@ func setCORS() gin.HandlerFunc {
 		},
 		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
 		AllowHeaders: []string{"Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "X-Forwarded-For",
-			"X-CSRF-Token", "X-Max"},
+			"X-CSRF-Token", "X-Max", "Idempotency-Key", "If-None-Match", "X-Robots-Override"},
 		AllowCredentials: true,
 		MaxAge:           cfg.CorsMaxAgeHours,
 	})
 }
 
-func limitBodySize() gin.HandlerFunc {
+// limitConcurrency rejects the requests with 503 while the max number of requests is in flight, so the load is shed
+// instead of queuing the requests unboundedly. The requests to the skipped paths, e.g. the health check, are always
+// served.
+func limitConcurrency(maxRequests int, skipPaths ...string) gin.HandlerFunc {
+	inFlight := make(chan struct{}, maxRequests)
+	return func(c *gin.Context) {
+		if slices.Contains(skipPaths, c.Request.URL.Path) {
+			return
+		}
+		select {
+		case inFlight <- struct{}{}:
+			defer func() { <-inFlight }()
+			c.Next()
+		default:
+			metrics.RequestsShed.Inc()
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				gin.H{"error": "too many concurrent requests. Retry later"})
+		}
+	}
+}
+
+// requireClientUserAgent rejects the requests without the User-Agent header with 400, so the traffic of this API
+// can be attributed to clients. It is the header of the request itself, not the 'user_agent' query parameter.
+// The requests to the skipped paths, e.g. the health check, are always served.
+func requireClientUserAgent(skipPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if slices.Contains(skipPaths, c.Request.URL.Path) {
+			return
+		}
+		if strings.TrimSpace(c.GetHeader("User-Agent")) == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "'User-Agent' header is required"})
+		}
+	}
+}
+
+// normalizeQuery trims the whitespace around the 'url' and 'user_agent' query parameters, e.g. from poorly encoded
+// clients, and normalizes the url (see util.NormalizeUrl), so all endpoints and cache keys see the same values.
+// If assumeScheme is set, it is prepended to the url without a scheme, otherwise such url is rejected by the handlers.
+func normalizeQuery(assumeScheme string) gin.HandlerFunc {
+	normalizeUrl := func(url string) string {
+		return util.NormalizeUrl(util.AssumeScheme(strings.TrimSpace(url), assumeScheme))
+	}
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		changed := false
+		for name, normalize := range map[string]func(string) string{
+			"url":        normalizeUrl,
+			"user_agent": strings.TrimSpace,
+		} {
+			values := query[name]
+			for i, value := range values {
+				if normalized := normalize(value); normalized != value {
+					values[i] = normalized
+					changed = true
+				}
+			}
+		}
+		if changed {
+			c.Request.URL.RawQuery = query.Encode()
+		}
+	}
+}
+
+// redactUrlUserinfo replaces the credentials of the 'url' query parameter before the request is logged.
+// The handlers still see that the url had credentials and reject or strip it.
+func redactUrlUserinfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.Request.URL.RawQuery, "%40") && !strings.Contains(c.Request.URL.RawQuery, "@") {
+			return
+		}
+		query := c.Request.URL.Query()
+		urls, ok := query["url"]
+		if !ok {
+			return
+		}
+		for i, url := range urls {
+			urls[i] = util.RedactUserinfo(url)
+		}
+		c.Request.URL.RawQuery = query.Encode()
+	}
+}
+
+// limitBodySize limits the request body to the max bytes. A request with a longer Content-Length is rejected
+// with 413 before the handler is called. The group limits are applied after the global one, so they can only
+// lower it.
+func limitBodySize(maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBodySize*1024*1024)
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge,
+				gin.H{"error": fmt.Sprintf("request body exceeds the limit of %d bytes", maxBytes)})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
 	}
 }
 
+// maxRobotsTxtBodySize returns the body limit of the requests with robots.txt content, the same as the limit
+// of robots.txt from origin ('http_client.max_robots_size'), or the global 'max_body_size' if it is not set.
+func maxRobotsTxtBodySize() int64 {
+	return cfg.MaxRobotsSize()
+}
+
 func apiKeyCheck() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader("X-API-Key")
@@ -175,17 +440,83 @@ func apiKeyCheck() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if usage != nil {
+			usage.Increment(apiKeyHash)
+		}
 
 		c.Next()
 	}
 }
 
+// recovery responds with 500 and the JSON error with a generated request id if the handler panics. The panic is
+// logged with the stack and the request id, so a client report can be correlated with the log.
+func recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				// the connection is aborted on purpose, there is nothing to respond
+				panic(err)
+			}
+			requestId := util.GenerateId()
+			slog.Error("panic recovered.", slog.Any("err", err), slog.String("request_id", requestId),
+				slog.String("method", c.Request.Method), slog.String("path", c.Request.URL.Path),
+				slog.String(logging.StackKey, string(debug.Stack())))
+			c.Header("X-Request-Id", requestId)
+			c.AbortWithStatusJSON(http.StatusInternalServerError,
+				gin.H{"error": "internal server error", "request_id": requestId})
+		}()
+		c.Next()
+	}
+}
+
+// apiKeyCheckForHeader requires the api key only for the requests with the header.
+func apiKeyCheckForHeader(header string) gin.HandlerFunc {
+	check := apiKeyCheck()
+	return func(c *gin.Context) {
+		if c.GetHeader(header) == "" {
+			c.Next()
+			return
+		}
+		check(c)
+	}
+}
+
+// allowQuery rejects the requests with query parameters other than the allowed ones with 400 if 'strict_query'
+// is enabled, e.g. to catch a typo like '?ur=' instead of '?url='. The parameters are ignored otherwise.
+func allowQuery(allowed ...string) gin.HandlerFunc {
+	if !cfg.StrictQuery {
+		return func(c *gin.Context) {}
+	}
+	return func(c *gin.Context) {
+		var unexpected []string
+		for key := range c.Request.URL.Query() {
+			if !slices.Contains(allowed, key) {
+				unexpected = append(unexpected, key)
+			}
+		}
+		if len(unexpected) > 0 {
+			slices.Sort(unexpected)
+			c.AbortWithStatusJSON(http.StatusBadRequest,
+				gin.H{"error": fmt.Sprintf("unsupported query parameters: %s", strings.Join(unexpected, ", "))})
+		}
+	}
+}
+
 func hashAPIKey(apiKey string) string {
 	hash := sha256.Sum256([]byte(apiKey))
 	return hex.EncodeToString(hash[:])
 }
 
 func setupLogger() *slog.Logger {
+	return newLogger(os.Stdout)
+}
+
+// newLogger creates the logger of the config writing to w and sets it as the default.
+func newLogger(w io.Writer) *slog.Logger {
 	resolvedLogLevel := func() slog.Level {
 		envLogLevel := strings.ToLower(cfg.LogLevel)
 		switch envLogLevel {
@@ -206,19 +537,28 @@ func setupLogger() *slog.Logger {
 		return a
 	}
 
-	var logger *slog.Logger
+	var logHandler slog.Handler
 	if strings.ToLower(cfg.LogType) == "json" {
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		logHandler = slog.NewJSONHandler(w, &slog.HandlerOptions{
 			AddSource:   true,
 			Level:       resolvedLogLevel(),
-			ReplaceAttr: replaceAttrs}))
+			ReplaceAttr: replaceAttrs})
 	} else {
-		logger = slog.New(tint.NewHandler(os.Stdout, &tint.Options{
+		logHandler = tint.NewHandler(w, &tint.Options{
 			AddSource:   true,
 			Level:       resolvedLogLevel(),
 			ReplaceAttr: replaceAttrs,
-			NoColor:     false}))
+			NoColor:     false})
 	}
+	// the 'log' section is optional, without it nothing is sampled and no stack is logged
+	logSettings := cfg.LogSettings
+	if logSettings == nil {
+		logSettings = &config.LogConfig{}
+	}
+	if logSettings.IncludeStackOnError {
+		logHandler = logging.NewStackHandler(logHandler)
+	}
+	logger := slog.New(logging.NewSamplingHandler(logHandler, logSettings.SampleRate))
 
 	slog.SetDefault(logger)
 	logger.Debug("debug messages are enabled.")
@@ -276,17 +616,16 @@ func setupDatabase() *sql.DB {
 	return database
 }
 
-func closeDatabase() {
-	log.Info("closing database connection.")
-	err := db.Close()
-	if err != nil {
-		log.Error("failed to close database connection.", slog.String("err", err.Error()))
+func applyMigrations() {
+	log.Info("applying database migrations...")
+	if err := persistence.Migrate(db, database.Migrations(), log); err != nil {
+		log.Error("failed to apply database migrations.", slog.String("err", err.Error()))
+		os.Exit(1)
 	}
+	log.Info("database migrations applied.")
 }
 
 func setupHttpClient() *http.Client {
-	return &http.Client{
-		Transport: http.DefaultTransport,
-		Timeout:   cfg.HttpClientSettings.RequestTimeout,
-	}
+	log.Info("robots.txt fetch user agent.", slog.String("user_agent", cfg.HttpClientSettings.UserAgent))
+	return httpclient.NewHttpClient(cfg.HttpClientSettings)
 }
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IliaW/robots-api/config"
+	"github.com/IliaW/robots-api/docs"
+	"github.com/IliaW/robots-api/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HttpServer_BasePath(t *testing.T) {
+	testSet := []struct {
+		name                string
+		basePath            string
+		path                string
+		expectedStatusCode  int
+		expectedSwaggerPath string
+	}{
+		{
+			name:                "routes are reachable without base path",
+			basePath:            "",
+			path:                "/ping",
+			expectedStatusCode:  http.StatusOK,
+			expectedSwaggerPath: "/robots/v1",
+		},
+		{
+			name:                "routes are reachable under base path",
+			basePath:            "/robots-service",
+			path:                "/robots-service/ping",
+			expectedStatusCode:  http.StatusOK,
+			expectedSwaggerPath: "/robots-service/robots/v1",
+		},
+		{
+			name:                "routes are not reachable outside base path",
+			basePath:            "/robots-service/",
+			path:                "/ping",
+			expectedStatusCode:  http.StatusNotFound,
+			expectedSwaggerPath: "/robots-service/robots/v1",
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg = &config.Config{
+				Env:            "test",
+				BasePath:       test.basePath,
+				RobotsUrlPath:  "/robots/v1",
+				MaxBodySize:    2,
+				RobotsSettings: &config.RobotsConfig{},
+			}
+
+			req, _ := http.NewRequest("GET", test.path, nil)
+			w := httptest.NewRecorder()
+			httpServer().ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			assert.Equal(tt, test.expectedSwaggerPath, docs.SwaggerInfo.BasePath)
+		})
+	}
+}
+
+func Test_HttpServer_RedactUrlUserinfo(t *testing.T) {
+	var logs bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &logs
+	defer func() { gin.DefaultWriter = defaultWriter }()
+	cfg = &config.Config{
+		Env:            "test",
+		RobotsUrlPath:  "/robots/v1",
+		MaxBodySize:    2,
+		RobotsSettings: &config.RobotsConfig{},
+	}
+
+	req, _ := http.NewRequest("GET", "/not-found?url=https%3A%2F%2Fuser%3Asecret%40example.com%2Fx", nil)
+	w := httptest.NewRecorder()
+	httpServer().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, logs.String(), "/not-found?url=https%3A%2F%2Fredacted%40example.com%2Fx")
+	assert.NotContains(t, logs.String(), "secret")
+	assert.NotContains(t, w.Body.String(), "secret")
+}
+
+func Test_CloserRegistry_CloseAll(t *testing.T) {
+	var closed []string
+	registry := &closerRegistry{timeout: 50 * time.Millisecond}
+	registry.register("database", func() error {
+		closed = append(closed, "database")
+		return nil
+	})
+	registry.register("queue", func() error {
+		closed = append(closed, "queue")
+		return errors.New("flush failed")
+	})
+	release := make(chan struct{})
+	defer close(release)
+	registry.register("stuck", func() error {
+		<-release
+		return nil
+	})
+	registry.register("cache", func() error {
+		closed = append(closed, "cache")
+		return nil
+	})
+
+	err := registry.closeAll(slog.Default())
+
+	assert.Equal(t, []string{"cache", "queue", "database"}, closed)
+	assert.ErrorContains(t, err, "stuck: close timeout of 50ms exceeded")
+	assert.ErrorContains(t, err, "queue: flush failed")
+	assert.NoError(t, registry.closeAll(slog.Default()))
+}
+
+func Test_ApiKeyCheckForHeader(t *testing.T) {
+	testSet := []struct {
+		name               string
+		header             string
+		expectedStatusCode int
+	}{
+		{name: "api key is not required without the header", header: "", expectedStatusCode: http.StatusOK},
+		{name: "api key is required with the header", header: "VXNlci1hZ2VudDogKg==",
+			expectedStatusCode: http.StatusUnauthorized},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			r := gin.New()
+			r.GET("/scrape-allowed", apiKeyCheckForHeader("X-Robots-Override"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req, _ := http.NewRequest("GET", "/scrape-allowed", nil)
+			if test.header != "" {
+				req.Header.Set("X-Robots-Override", test.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_HttpServer_BodySizeLimits(t *testing.T) {
+	testSet := []struct {
+		name               string
+		method             string
+		path               string
+		bodySize           int
+		expectedStatusCode int
+	}{
+		{name: "request without a body rejects any body", method: "GET", path: "/robots/v1/scrape-allowed",
+			bodySize: 1, expectedStatusCode: http.StatusRequestEntityTooLarge},
+		{name: "admin request without a body rejects any body", method: "DELETE", path: "/robots/v1/custom-rule",
+			bodySize: 1, expectedStatusCode: http.StatusRequestEntityTooLarge},
+		{name: "custom rule is limited by the robots.txt size", method: "POST", path: "/robots/v1/custom-rule",
+			bodySize: 2 * 1024, expectedStatusCode: http.StatusRequestEntityTooLarge},
+		{name: "inline robots.txt is limited by the robots.txt size", method: "POST",
+			path: "/robots/v1/scrape-allowed", bodySize: 2 * 1024, expectedStatusCode: http.StatusRequestEntityTooLarge},
+		// the body over the robots.txt size passes the body limit and is rejected by the api key check
+		{name: "bulk request is not limited by the robots.txt size", method: "POST",
+			path: "/robots/v1/scrape-allowed/paths", bodySize: 2 * 1024, expectedStatusCode: http.StatusUnauthorized},
+		{name: "batch request requires the api key", method: "POST", path: "/robots/v1/scrape-allowed/batch",
+			bodySize: 2 * 1024, expectedStatusCode: http.StatusUnauthorized},
+		{name: "bulk request is limited by the max body size", method: "POST", path: "/robots/v1/scrape-allowed/paths",
+			bodySize: 3 * 1024 * 1024, expectedStatusCode: http.StatusRequestEntityTooLarge},
+		{name: "cache invalidation is limited by the max body size", method: "POST",
+			path: "/robots/v1/cache/invalidate", bodySize: 3 * 1024 * 1024,
+			expectedStatusCode: http.StatusRequestEntityTooLarge},
+	}
+	cfg = &config.Config{
+		Env:                "test",
+		RobotsUrlPath:      "/robots/v1",
+		MaxBodySize:        2,
+		HttpClientSettings: &config.HttpClientConfig{MaxRobotsSize: 1},
+		RobotsSettings:     &config.RobotsConfig{},
+	}
+	server := httpServer()
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			req, _ := http.NewRequest(test.method, test.path, bytes.NewReader(bytes.Repeat([]byte("x"), test.bodySize)))
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_AllowQuery(t *testing.T) {
+	testSet := []struct {
+		name               string
+		strictQuery        bool
+		target             string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{name: "typo is rejected in strict mode", strictQuery: true,
+			target:             "/scrape-allowed?ur=https://example.com&user_agent=bot&expalin=true",
+			expectedResponse:   "{\"error\":\"unsupported query parameters: expalin, ur\"}",
+			expectedStatusCode: http.StatusBadRequest},
+		{name: "allowed parameters in strict mode", strictQuery: true,
+			target: "/scrape-allowed?url=https://example.com&user_agent=bot", expectedStatusCode: http.StatusOK},
+		{name: "typo is ignored without strict mode", strictQuery: false,
+			target: "/scrape-allowed?ur=https://example.com&user_agent=bot", expectedStatusCode: http.StatusOK},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg = &config.Config{StrictQuery: test.strictQuery}
+			r := gin.New()
+			r.GET("/scrape-allowed", allowQuery("url", "user_agent", "explain"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req, _ := http.NewRequest("GET", test.target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_Recovery(t *testing.T) {
+	r := gin.New()
+	r.Use(recovery())
+	r.GET("/panic", func(c *gin.Context) {
+		panic("unexpected state")
+	})
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal server error", response["error"])
+	assert.Regexp(t, "^[0-9a-f]{32}$", response["request_id"])
+	assert.Equal(t, response["request_id"], w.Header().Get("X-Request-Id"))
+}
+
+func Test_NormalizeQuery(t *testing.T) {
+	r := gin.New()
+	r.Use(normalizeQuery(""))
+	r.GET("/scrape-allowed", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Query("url")+"|"+c.Query("user_agent")+"|"+c.Query("note"))
+	})
+	req, _ := http.NewRequest("GET", "/scrape-allowed?url=%20HTTPS://Example.COM/Page%20&user_agent=%20bot%09"+
+		"&note=%20kept%20", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://example.com/Page|bot| kept ", w.Body.String())
+}
+
+func Test_NormalizeQuery_AssumeScheme(t *testing.T) {
+	testSet := []struct {
+		name               string
+		assumeScheme       string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{name: "url without scheme is accepted with the assumed scheme", assumeScheme: "https",
+			expectedResponse: "https://www.example.com/Path|www.example.com", expectedStatusCode: http.StatusOK},
+		{name: "url without scheme is rejected without the assumed scheme", assumeScheme: "",
+			expectedResponse: "invalid url. Url should contain scheme and hostname", expectedStatusCode: http.StatusBadRequest},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			r := gin.New()
+			r.Use(normalizeQuery(test.assumeScheme))
+			r.GET("/robots-meta", func(c *gin.Context) {
+				domain, err := util.GetDomain(c.Query("url"))
+				if err != nil {
+					c.String(http.StatusBadRequest, err.Error())
+					return
+				}
+				c.String(http.StatusOK, c.Query("url")+"|"+domain)
+			})
+			req, _ := http.NewRequest("GET", "/robots-meta?url=%20WWW.Example.com/Path", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func Test_LimitConcurrency(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(limitConcurrency(1, "/ping"))
+	r.GET("/slow", func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	done := make(chan int)
+	go func() { done <- serve("/slow").Code }()
+	<-entered
+
+	w := serve("/fast")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "{\"error\":\"too many concurrent requests. Retry later\"}", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("/ping").Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, serve("/fast").Code)
+}
+
+func Test_RequireClientUserAgent(t *testing.T) {
+	r := gin.New()
+	r.Use(requireClientUserAgent("/ping"))
+	r.GET("/scrape-allowed", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	testSet := []struct {
+		name               string
+		path               string
+		userAgent          string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{name: "with user agent", path: "/scrape-allowed", userAgent: "crawler/1.0", expectedStatusCode: http.StatusOK},
+		{
+			name:               "without user agent",
+			path:               "/scrape-allowed",
+			expectedResponse:   "{\"error\":\"'User-Agent' header is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "blank user agent",
+			path:               "/scrape-allowed",
+			userAgent:          " ",
+			expectedResponse:   "{\"error\":\"'User-Agent' header is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{name: "skipped path without user agent", path: "/ping", expectedStatusCode: http.StatusOK},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			req, _ := http.NewRequest("GET", test.path, nil)
+			if test.userAgent != "" {
+				req.Header.Set("User-Agent", test.userAgent)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, test.expectedResponse, w.Body.String())
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func Test_SetupLogger_WithoutLogSection(t *testing.T) {
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+	cfg = &config.Config{LogLevel: "error"}
+
+	assert.NotPanics(t, func() { assert.NotNil(t, setupLogger()) })
+}
+
+func Test_NewLogger_IncludeStackOnError(t *testing.T) {
+	testSet := []struct {
+		name                string
+		includeStackOnError bool
+	}{
+		{name: "stack is logged", includeStackOnError: true},
+		{name: "stack is not logged by default", includeStackOnError: false},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			defaultLogger := slog.Default()
+			tt.Cleanup(func() { slog.SetDefault(defaultLogger) })
+			cfg = &config.Config{
+				LogLevel:    "info",
+				LogType:     "json",
+				LogSettings: &config.LogConfig{IncludeStackOnError: test.includeStackOnError},
+			}
+			var logs bytes.Buffer
+			newLogger(&logs)
+
+			// the handlers log the 500 errors with the default logger
+			slog.Error("database request failed.", slog.String("err", "table is corrupted"))
+			slog.Warn("failed to get custom rule.")
+
+			lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+			assert.Len(tt, lines, 2)
+			assert.Contains(tt, lines[0], "database request failed.")
+			assert.Equal(tt, test.includeStackOnError, strings.Contains(lines[0], "\"stack\":\"goroutine"))
+			assert.NotContains(tt, lines[1], "\"stack\"")
+		})
+	}
+}
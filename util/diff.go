@@ -0,0 +1,102 @@
+package util
+
+import "strings"
+
+const (
+	DiffEqual   = "equal"
+	DiffRemoved = "removed"
+	DiffAdded   = "added"
+)
+
+// maxDiffCells limits the size of the longest common subsequence table. The lines that differ between larger
+// inputs are reported as removed and added without matching.
+const maxDiffCells = 4_000_000
+
+// DiffLine is a line of the line-level diff. Op is DiffEqual, DiffRemoved (only in the old text)
+// or DiffAdded (only in the new text).
+type DiffLine struct {
+	Op   string
+	Line string
+}
+
+// DiffLines returns the line-level diff of the old and new text. The common prefix and suffix are kept as is and
+// the lines between are matched by the longest common subsequence. CRLF line endings are handled as LF.
+func DiffLines(oldText, newText string) []DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Line: line})
+	}
+	diff = append(diff, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Line: line})
+	}
+	return diff
+}
+
+func diffMiddle(a, b []string) []DiffLine {
+	var diff []DiffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			diff = append(diff, DiffLine{Op: DiffRemoved, Line: line})
+		}
+		for _, line := range b {
+			diff = append(diff, DiffLine{Op: DiffAdded, Line: line})
+		}
+		return diff
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffRemoved, Line: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffAdded, Line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Op: DiffRemoved, Line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Op: DiffAdded, Line: b[j]})
+	}
+	return diff
+}
+
+// splitLines splits the text into lines. The final line break doesn't start an empty line.
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiffLines(t *testing.T) {
+	testSet := []struct {
+		name         string
+		oldText      string
+		newText      string
+		expectedDiff []DiffLine
+	}{
+		{
+			name:    "identical content",
+			oldText: "User-agent: *\nDisallow: /private\n",
+			newText: "User-agent: *\r\nDisallow: /private",
+			expectedDiff: []DiffLine{
+				{Op: DiffEqual, Line: "User-agent: *"},
+				{Op: DiffEqual, Line: "Disallow: /private"},
+			},
+		},
+		{
+			name:    "changed, removed and added lines",
+			oldText: "User-agent: *\nDisallow: /private\nDisallow: /tmp\nSitemap: https://example.com/sitemap.xml",
+			newText: "User-agent: *\nDisallow: /admin\nDisallow: /tmp\nAllow: /tmp/public\n" +
+				"Sitemap: https://example.com/sitemap.xml",
+			expectedDiff: []DiffLine{
+				{Op: DiffEqual, Line: "User-agent: *"},
+				{Op: DiffRemoved, Line: "Disallow: /private"},
+				{Op: DiffAdded, Line: "Disallow: /admin"},
+				{Op: DiffEqual, Line: "Disallow: /tmp"},
+				{Op: DiffAdded, Line: "Allow: /tmp/public"},
+				{Op: DiffEqual, Line: "Sitemap: https://example.com/sitemap.xml"},
+			},
+		},
+		{
+			name:         "empty old content",
+			oldText:      "",
+			newText:      "User-agent: *",
+			expectedDiff: []DiffLine{{Op: DiffAdded, Line: "User-agent: *"}},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expectedDiff, DiffLines(test.oldText, test.newText))
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateId returns a random 32 characters hex string.
+func GenerateId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,177 @@
+package util
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jimsmart/grobotstxt"
+)
+
+// MatchedGroup describes the robots.txt groups grobotstxt applies to the user agent.
+type MatchedGroup struct {
+	UserAgents []string `json:"user_agents"`
+	Wildcard   bool     `json:"wildcard"`
+}
+
+// MatchGroup returns the user-agent values of the groups that apply to the user agent. Consecutive user-agent
+// lines are one group, so the rules after them apply to all the listed agents (RFC 9309). The groups of the user
+// agent are selected if any of their user-agent values has the same product token, e.g. 'Googlebot/2.1' for
+// 'googlebot', even if the group lists '*' too. Otherwise, the '*' groups are selected. The user agents are empty
+// if no group applies.
+func MatchGroup(robotsTxt, userAgent string) *MatchedGroup {
+	s := &groupSelector{}
+	grobotstxt.Parse(robotsTxt, s)
+
+	var specific, global []string
+	for _, group := range s.groups {
+		switch {
+		case isAgentGroup(group, userAgent):
+			specific = appendGroup(specific, group)
+		case slices.ContainsFunc(group, isGlobalAgent):
+			global = appendGroup(global, group)
+		}
+	}
+	if len(specific) > 0 {
+		return &MatchedGroup{UserAgents: specific}
+	}
+	if len(global) > 0 {
+		return &MatchedGroup{UserAgents: global, Wildcard: true}
+	}
+	return &MatchedGroup{UserAgents: []string{}}
+}
+
+// VisitTime is the preferred crawl window of the Visit-time directive in UTC, e.g. '0100' to '0500'.
+type VisitTime struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MatchVisitTime returns the Visit-time of the groups that apply to the user agent, selected the same way as
+// MatchGroup: the groups of the user agent if there are any, otherwise the '*' groups. The '*' groups don't apply
+// to a user agent with its own group, even if its group has no Visit-time. The first valid 'HHMM-HHMM' value of
+// the groups is used. Returns nil if the applying groups have no valid Visit-time.
+func MatchVisitTime(robotsTxt, userAgent string) *VisitTime {
+	s := &groupSelector{}
+	grobotstxt.Parse(robotsTxt, s)
+
+	var agent, global *VisitTime
+	seenAgent := false
+	for i, group := range s.groups {
+		visitTime := s.visitTimes[i]
+		switch {
+		case isAgentGroup(group, userAgent):
+			seenAgent = true
+			if agent == nil {
+				agent = visitTime
+			}
+		case global == nil && slices.ContainsFunc(group, isGlobalAgent):
+			global = visitTime
+		}
+	}
+	if seenAgent {
+		return agent
+	}
+	return global
+}
+
+// parseVisitTime parses the 'HHMM-HHMM' value, e.g. '0100-0500'. Returns nil if the value is invalid.
+func parseVisitTime(value string) *VisitTime {
+	from, to, found := strings.Cut(value, "-")
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !found || !isHourMinute(from) || !isHourMinute(to) {
+		return nil
+	}
+	return &VisitTime{From: from, To: to}
+}
+
+// isHourMinute reports whether the value is a valid 'HHMM' time, e.g. '2359'.
+func isHourMinute(value string) bool {
+	if len(value) != 4 || strings.Trim(value, "0123456789") != "" {
+		return false
+	}
+	return value[:2] < "24" && value[2:] < "60"
+}
+
+// isAgentGroup reports whether any user-agent value of the group has the product token of the user agent.
+func isAgentGroup(group []string, userAgent string) bool {
+	return slices.ContainsFunc(group, func(value string) bool {
+		return !isGlobalAgent(value) && strings.EqualFold(extractUserAgent(value), userAgent)
+	})
+}
+
+// DeclaredUserAgents returns the distinct user agents of the user-agent lines in the declaration order,
+// e.g. ["*", "googlebot", "adsbot-google"]. The product tokens are lower-case, because the user agents are matched
+// case-insensitively.
+func DeclaredUserAgents(robotsTxt string) []string {
+	s := &groupSelector{}
+	grobotstxt.Parse(robotsTxt, s)
+
+	agents := []string{}
+	for _, group := range s.groups {
+		for _, value := range group {
+			agent := "*"
+			if !isGlobalAgent(value) {
+				agent = strings.ToLower(extractUserAgent(value))
+			}
+			if agent != "" && !slices.Contains(agents, agent) {
+				agents = append(agents, agent)
+			}
+		}
+	}
+	return agents
+}
+
+func appendGroup(agents, group []string) []string {
+	for _, value := range group {
+		if !slices.Contains(agents, value) {
+			agents = append(agents, value)
+		}
+	}
+	return agents
+}
+
+// groupSelector implements grobotstxt.ParseHandler and collects the user-agent values and the Visit-time of every
+// group. A new group starts with the user-agent line after a rule.
+type groupSelector struct {
+	groups     [][]string
+	visitTimes map[int]*VisitTime
+	lastAgent  bool
+}
+
+func (s *groupSelector) HandleRobotsStart() {}
+
+func (s *groupSelector) HandleRobotsEnd() {}
+
+func (s *groupSelector) HandleUserAgent(_ int, value string) {
+	if !s.lastAgent {
+		s.groups = append(s.groups, nil)
+	}
+	s.groups[len(s.groups)-1] = append(s.groups[len(s.groups)-1], value)
+	s.lastAgent = true
+}
+
+func (s *groupSelector) HandleAllow(_ int, _ string) {
+	s.lastAgent = false
+}
+
+func (s *groupSelector) HandleDisallow(_ int, _ string) {
+	s.lastAgent = false
+}
+
+func (s *groupSelector) HandleSitemap(_ int, _ string) {}
+
+func (s *groupSelector) HandleUnknownAction(_ int, action, value string) {
+	if !strings.EqualFold(action, "visit-time") || len(s.groups) == 0 {
+		return
+	}
+	group := len(s.groups) - 1
+	if _, ok := s.visitTimes[group]; ok {
+		return
+	}
+	if visitTime := parseVisitTime(value); visitTime != nil {
+		if s.visitTimes == nil {
+			s.visitTimes = make(map[int]*VisitTime)
+		}
+		s.visitTimes[group] = visitTime
+	}
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MatchGroup(t *testing.T) {
+	testSet := []struct {
+		name          string
+		robotsTxt     string
+		userAgent     string
+		expectedGroup *MatchedGroup
+	}{
+		{
+			name:          "exact agent match",
+			robotsTxt:     "User-agent: googlebot\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n",
+			userAgent:     "Googlebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"googlebot"}},
+		},
+		{
+			name:          "product token prefix match",
+			robotsTxt:     "User-agent: Googlebot/2.1\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n",
+			userAgent:     "googlebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"Googlebot/2.1"}},
+		},
+		{
+			name:          "group with several user agents",
+			robotsTxt:     "User-agent: bingbot\nUser-agent: googlebot\nDisallow: /private\n",
+			userAgent:     "googlebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"bingbot", "googlebot"}},
+		},
+		{
+			name:          "wildcard fallback",
+			robotsTxt:     "User-agent: googlebot\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n",
+			userAgent:     "somebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"*"}, Wildcard: true},
+		},
+		{
+			name:          "agent name is not matched by a longer product token",
+			robotsTxt:     "User-agent: googlebot-news\nDisallow: /\n\nUser-agent: *\nAllow: /\n",
+			userAgent:     "googlebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"*"}, Wildcard: true},
+		},
+		{
+			name:          "adjacent user agents share the group",
+			robotsTxt:     "User-agent: bingbot\nUser-agent: googlebot\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n",
+			userAgent:     "bingbot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"bingbot", "googlebot"}},
+		},
+		{
+			name:          "group of the user agent listing the wildcard too",
+			robotsTxt:     "User-agent: *\nUser-agent: googlebot\nDisallow: /private\n",
+			userAgent:     "googlebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{"*", "googlebot"}},
+		},
+		{
+			name:          "no group applies",
+			robotsTxt:     "User-agent: googlebot\nDisallow: /\n",
+			userAgent:     "somebot",
+			expectedGroup: &MatchedGroup{UserAgents: []string{}},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expectedGroup, MatchGroup(test.robotsTxt, test.userAgent))
+		})
+	}
+}
+
+func Test_AdjacentUserAgents(t *testing.T) {
+	robotsTxt := "User-agent: bingbot\n" +
+		"User-agent: googlebot\n" +
+		"Disallow: /private\n" +
+		"\n" +
+		"User-agent: *\n" +
+		"Disallow: /\n"
+	for _, userAgent := range []string{"bingbot", "googlebot"} {
+		t.Run(userAgent, func(tt *testing.T) {
+			explanation := Explain(robotsTxt, userAgent, "https://example.com/private/page")
+			assert.False(tt, explanation.Allowed)
+			assert.Equal(tt, &MatchedRule{Directive: DisallowDirective, Pattern: "/private", Line: 3}, explanation.Rule)
+			assert.False(tt, NewMatcher(false).AgentAllowed(robotsTxt, userAgent, "https://example.com/private/page"))
+			assert.True(tt, NewMatcher(false).AgentAllowed(robotsTxt, userAgent, "https://example.com/public"))
+		})
+	}
+}
+
+func Test_DeclaredUserAgents(t *testing.T) {
+	robotsTxt := "User-agent: *\n" +
+		"Disallow: /tmp\n" +
+		"\n" +
+		"User-agent: Googlebot/2.1\n" +
+		"User-agent: AdsBot-Google\n" +
+		"Disallow: /ads\n" +
+		"\n" +
+		"User-agent: googlebot\n" +
+		"User-agent: *\n" +
+		"Disallow: /private\n"
+
+	assert.Equal(t, []string{"*", "googlebot", "adsbot-google"}, DeclaredUserAgents(robotsTxt))
+	assert.Equal(t, []string{}, DeclaredUserAgents(""))
+}
+
+func Test_MatchVisitTime(t *testing.T) {
+	testSet := []struct {
+		name      string
+		robotsTxt string
+		userAgent string
+		expected  *VisitTime
+	}{
+		{
+			name:      "visit time of the user agent group",
+			robotsTxt: "User-agent: mybot\nVisit-time: 0100-0500\nDisallow: /private\n\nUser-agent: *\nDisallow: /",
+			userAgent: "mybot",
+			expected:  &VisitTime{From: "0100", To: "0500"},
+		},
+		{
+			name:      "visit time of the wildcard group",
+			robotsTxt: "User-agent: mybot\nDisallow: /private\n\nUser-agent: *\nvisit-time: 2200 - 0600\nDisallow: /",
+			userAgent: "otherbot",
+			expected:  &VisitTime{From: "2200", To: "0600"},
+		},
+		{
+			name:      "user agent group without visit time ignores the wildcard group",
+			robotsTxt: "User-agent: mybot\nDisallow: /private\n\nUser-agent: *\nVisit-time: 2200-0600\nDisallow: /",
+			userAgent: "mybot",
+			expected:  nil,
+		},
+		{
+			name: "visit time of the second group of the user agent",
+			robotsTxt: "User-agent: mybot\nDisallow: /private\n\nUser-agent: *\nVisit-time: 2200-0600\nDisallow: /\n\n" +
+				"User-agent: mybot\nVisit-time: 0100-0300",
+			userAgent: "mybot",
+			expected:  &VisitTime{From: "0100", To: "0300"},
+		},
+		{
+			name:      "absent visit time",
+			robotsTxt: "User-agent: *\nDisallow: /",
+			userAgent: "mybot",
+			expected:  nil,
+		},
+		{
+			name:      "invalid visit time is ignored",
+			robotsTxt: "User-agent: *\nVisit-time: 2500-0600\nVisit-time: 0100-0200\nDisallow: /",
+			userAgent: "mybot",
+			expected:  &VisitTime{From: "0100", To: "0200"},
+		},
+		{
+			name:      "visit time of other group",
+			robotsTxt: "User-agent: otherbot\nVisit-time: 0100-0500\nDisallow: /",
+			userAgent: "mybot",
+			expected:  nil,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, MatchVisitTime(test.robotsTxt, test.userAgent))
+		})
+	}
+}
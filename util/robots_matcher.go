@@ -0,0 +1,66 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/jimsmart/grobotstxt"
+)
+
+// Matcher decides whether the user agent is allowed to access the url by the robots.txt rules.
+type Matcher interface {
+	AgentAllowed(robotsTxt, userAgent, url string) bool
+	// UserAgent returns the user agent the robots.txt groups are matched with.
+	UserAgent(userAgent string) string
+}
+
+// NewMatcher returns the strict RFC 9309 matcher if strictRfc is true, otherwise the default Google-flavored matcher.
+func NewMatcher(strictRfc bool) Matcher {
+	if strictRfc {
+		return rfcMatcher{}
+	}
+	return googleMatcher{}
+}
+
+// googleMatcher matches the user agent as is, so 'MyBot/2.0' doesn't match the 'mybot' group.
+type googleMatcher struct{}
+
+func (googleMatcher) AgentAllowed(robotsTxt, userAgent, url string) bool {
+	if HasNoRules(robotsTxt) {
+		return true
+	}
+	return grobotstxt.AgentAllowed(robotsTxt, userAgent, url)
+}
+
+func (googleMatcher) UserAgent(userAgent string) string {
+	return userAgent
+}
+
+// rfcMatcher matches the groups by the product token of the user agent case-insensitively, as required by RFC 9309.
+// The version and comments are ignored, so 'MyBot/2.0 (+https://example.com)' matches the 'mybot' group.
+type rfcMatcher struct{}
+
+func (m rfcMatcher) AgentAllowed(robotsTxt, userAgent, url string) bool {
+	if HasNoRules(robotsTxt) {
+		return true
+	}
+	return grobotstxt.AgentAllowed(robotsTxt, m.UserAgent(userAgent), url)
+}
+
+func (rfcMatcher) UserAgent(userAgent string) string {
+	if token := extractUserAgent(userAgent); token != "" {
+		return token
+	}
+	return userAgent
+}
+
+// HasNoRules reports whether the robots.txt has only comments and blank lines. Such robots.txt allows everything,
+// so the decision doesn't need to parse it.
+func HasNoRules(robotsTxt string) bool {
+	for _, line := range robotsTxtLines(strings.TrimPrefix(robotsTxt, "\xef\xbb\xbf")) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Matcher(t *testing.T) {
+	robotsTxt := "User-agent: mybot\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n"
+	testSet := []struct {
+		name                   string
+		userAgent              string
+		url                    string
+		expectedDefault        bool
+		expectedStrictRfc      bool
+		expectedStrictRfcAgent string
+	}{
+		{
+			name:                   "user agent with version",
+			userAgent:              "MyBot/2.0",
+			url:                    "https://example.com/public",
+			expectedDefault:        false,
+			expectedStrictRfc:      true,
+			expectedStrictRfcAgent: "MyBot",
+		},
+		{
+			name:                   "user agent with version and comment",
+			userAgent:              "MyBot/2.0 (+https://example.com/bot)",
+			url:                    "https://example.com/private",
+			expectedDefault:        false,
+			expectedStrictRfc:      false,
+			expectedStrictRfcAgent: "MyBot",
+		},
+		{
+			name:                   "product token",
+			userAgent:              "MYBOT",
+			url:                    "https://example.com/public",
+			expectedDefault:        true,
+			expectedStrictRfc:      true,
+			expectedStrictRfcAgent: "MYBOT",
+		},
+		{
+			name:                   "other user agent",
+			userAgent:              "OtherBot/1.0",
+			url:                    "https://example.com/public",
+			expectedDefault:        false,
+			expectedStrictRfc:      false,
+			expectedStrictRfcAgent: "OtherBot",
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			defaultMatcher := NewMatcher(false)
+			strictRfcMatcher := NewMatcher(true)
+
+			assert.Equal(tt, test.expectedDefault, defaultMatcher.AgentAllowed(robotsTxt, test.userAgent, test.url))
+			assert.Equal(tt, test.expectedStrictRfc, strictRfcMatcher.AgentAllowed(robotsTxt, test.userAgent, test.url))
+			assert.Equal(tt, test.userAgent, defaultMatcher.UserAgent(test.userAgent))
+			assert.Equal(tt, test.expectedStrictRfcAgent, strictRfcMatcher.UserAgent(test.userAgent))
+		})
+	}
+}
+
+func Test_Matcher_LenientDirectives(t *testing.T) {
+	robotsTxt := "User-agent:mybot\n" +
+		"Disallow:/admin\n" +
+		"Allow: /admin/public  \t\n" +
+		"Disallow /private\n" +
+		"Disallow: /tmp   \n"
+	testSet := []struct {
+		name     string
+		url      string
+		expected bool
+	}{
+		{name: "no space after colon", url: "https://example.com/admin/users", expected: false},
+		{name: "trailing whitespace", url: "https://example.com/admin/public/page", expected: true},
+		{name: "no colon", url: "https://example.com/private", expected: false},
+		{name: "trailing spaces", url: "https://example.com/tmp/file", expected: false},
+		{name: "no matching rule", url: "https://example.com/blog", expected: true},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			for _, strictRfc := range []bool{false, true} {
+				assert.Equal(tt, test.expected, NewMatcher(strictRfc).AgentAllowed(robotsTxt, "mybot", test.url))
+			}
+			assert.Equal(tt, test.expected, Explain(robotsTxt, "mybot", test.url).Allowed)
+		})
+	}
+}
+
+func Test_HasNoRules(t *testing.T) {
+	testSet := []struct {
+		name      string
+		robotsTxt string
+		expected  bool
+	}{
+		{name: "empty", robotsTxt: "", expected: true},
+		{name: "whitespace", robotsTxt: " \n\t\r\n", expected: true},
+		{name: "comments", robotsTxt: "# robots.txt for example.com\n\n  # no rules yet\r\n", expected: true},
+		{name: "comments with byte order mark", robotsTxt: "\xef\xbb\xbf# nothing here", expected: true},
+		{name: "rule after comments", robotsTxt: "# comment\nUser-agent: *\nDisallow: /", expected: false},
+		{name: "unknown directive", robotsTxt: "Crawl-delay: 10", expected: false},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, HasNoRules(test.robotsTxt))
+			if test.expected {
+				assert.True(tt, NewMatcher(false).AgentAllowed(test.robotsTxt, "mybot", "https://example.com/private"))
+				assert.True(tt, NewMatcher(true).AgentAllowed(test.robotsTxt, "mybot", "https://example.com/private"))
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"strings"
+)
+
+// robotsTagDirectives are the X-Robots-Tag directives, that have a value after ':'. They are not user agent prefixes.
+var robotsTagDirectives = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// IsIndexAllowed reports whether the X-Robots-Tag header values allow the user agent to index the page.
+// A value can be limited to a user agent with the 'useragent:' prefix, e.g. 'googlebot: noindex'.
+// The page is not allowed to be indexed if the 'noindex' or 'none' directive applies to the user agent.
+func IsIndexAllowed(robotsTags []string, userAgent string) bool {
+	for _, tag := range robotsTags {
+		directives := tag
+		if prefix, rest, ok := strings.Cut(tag, ":"); ok {
+			prefix = strings.ToLower(strings.TrimSpace(prefix))
+			if !robotsTagDirectives[prefix] && !strings.Contains(prefix, ",") {
+				if !strings.EqualFold(prefix, extractUserAgent(userAgent)) {
+					continue
+				}
+				directives = rest
+			}
+		}
+		for _, directive := range strings.Split(directives, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			if directive == "noindex" || directive == "none" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsIndexAllowed(t *testing.T) {
+	testSet := []struct {
+		name       string
+		robotsTags []string
+		userAgent  string
+		expected   bool
+	}{
+		{
+			name:       "no header",
+			robotsTags: nil,
+			userAgent:  "bot",
+			expected:   true,
+		},
+		{
+			name:       "noindex",
+			robotsTags: []string{"noindex"},
+			userAgent:  "bot",
+			expected:   false,
+		},
+		{
+			name:       "none",
+			robotsTags: []string{"NONE"},
+			userAgent:  "bot",
+			expected:   false,
+		},
+		{
+			name:       "noindex in the list of directives",
+			robotsTags: []string{"nofollow, noindex"},
+			userAgent:  "bot",
+			expected:   false,
+		},
+		{
+			name:       "directives without noindex",
+			robotsTags: []string{"nofollow, max-snippet: 20", "unavailable_after: 2025-01-01"},
+			userAgent:  "bot",
+			expected:   true,
+		},
+		{
+			name:       "noindex for the user agent",
+			robotsTags: []string{"Bot: noindex"},
+			userAgent:  "bot/1.0",
+			expected:   false,
+		},
+		{
+			name:       "noindex for another user agent",
+			robotsTags: []string{"googlebot: noindex", "nofollow"},
+			userAgent:  "bot",
+			expected:   true,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, IsIndexAllowed(test.robotsTags, test.userAgent))
+		})
+	}
+}
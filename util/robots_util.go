@@ -0,0 +1,289 @@
+package util
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+
+	"github.com/jimsmart/grobotstxt"
+)
+
+const (
+	AllowDirective    = "allow"
+	DisallowDirective = "disallow"
+)
+
+const (
+	ConfidenceHigh = "high"
+	ConfidenceLow  = "low"
+)
+
+// Explanation describes which robots.txt rule decided whether the url is allowed for the user agent.
+// Confidence is low if the robots.txt has validation warnings or is truncated, so the decision is less trustworthy.
+type Explanation struct {
+	Allowed    bool         `json:"allowed"`
+	Rule       *MatchedRule `json:"rule"`
+	Note       string       `json:"note,omitempty"`
+	Confidence string       `json:"confidence,omitempty"`
+}
+
+// MatchedRule is the robots.txt rule that won the match.
+type MatchedRule struct {
+	Directive string `json:"directive"`
+	Pattern   string `json:"pattern"`
+	Line      int    `json:"line"`
+}
+
+// Explain reproduces the grobotstxt decision for the user agent and url and reports the rule that decided it.
+// The most specific (longest) matching rule wins. When an allow and a disallow rule of equal length both match,
+// the allow rule wins.
+func Explain(robotsTxt, userAgent, url string) *Explanation {
+	if HasNoRules(robotsTxt) {
+		return &Explanation{Allowed: true, Note: "robots.txt has no rules"}
+	}
+	e := &explainer{
+		userAgent: userAgent,
+		path:      getPathParamsQuery(url),
+		specific:  newGroupMatch(),
+		global:    newGroupMatch(),
+	}
+	grobotstxt.Parse(robotsTxt, e)
+
+	if e.specific.matched() {
+		return e.specific.explain()
+	}
+	if e.everSeenSpecificAgent {
+		return &Explanation{Allowed: true, Note: "the group for the user agent has no rule matching the url"}
+	}
+	if e.global.matched() {
+		return e.global.explain()
+	}
+
+	return &Explanation{Allowed: true, Note: "no rule matches the url"}
+}
+
+type ruleMatch struct {
+	priority int
+	rule     *MatchedRule
+}
+
+func (m *ruleMatch) update(priority int, rule *MatchedRule) {
+	if priority > m.priority {
+		m.priority = priority
+		m.rule = rule
+	}
+}
+
+type groupMatch struct {
+	allow    ruleMatch
+	disallow ruleMatch
+}
+
+func newGroupMatch() groupMatch {
+	return groupMatch{allow: ruleMatch{priority: -1}, disallow: ruleMatch{priority: -1}}
+}
+
+func (g *groupMatch) matched() bool {
+	return g.allow.priority > 0 || g.disallow.priority > 0
+}
+
+func (g *groupMatch) explain() *Explanation {
+	if g.disallow.priority > g.allow.priority {
+		return &Explanation{Allowed: false, Rule: g.disallow.rule}
+	}
+	e := &Explanation{Allowed: true, Rule: g.allow.rule}
+	if g.allow.priority == g.disallow.priority {
+		e.Note = "allow and disallow rules have equal specificity. The allow rule wins the tiebreak"
+	}
+	return e
+}
+
+// explainer implements grobotstxt.ParseHandler and follows the grouping rules of the grobotstxt matcher.
+type explainer struct {
+	userAgent             string
+	path                  string
+	seenGlobalAgent       bool
+	seenSpecificAgent     bool
+	everSeenSpecificAgent bool
+	seenSeparator         bool
+	specific              groupMatch
+	global                groupMatch
+}
+
+func (e *explainer) HandleRobotsStart() {}
+
+func (e *explainer) HandleRobotsEnd() {}
+
+func (e *explainer) HandleUserAgent(_ int, value string) {
+	if e.seenSeparator {
+		e.seenSpecificAgent, e.seenGlobalAgent, e.seenSeparator = false, false, false
+	}
+	if isGlobalAgent(value) {
+		e.seenGlobalAgent = true
+		return
+	}
+	if strings.EqualFold(extractUserAgent(value), e.userAgent) {
+		e.seenSpecificAgent = true
+		e.everSeenSpecificAgent = true
+	}
+}
+
+func (e *explainer) HandleAllow(lineNum int, value string) {
+	e.handleRule(lineNum, AllowDirective, value)
+	// 'index.htm' and 'index.html' are normalized to the directory, same as in grobotstxt
+	if slash := strings.LastIndex(value, "/"); slash >= 0 && strings.HasPrefix(value[slash:], "/index.htm") {
+		e.handleRule(lineNum, AllowDirective, value[:slash+1]+"$")
+	}
+}
+
+func (e *explainer) HandleDisallow(lineNum int, value string) {
+	e.handleRule(lineNum, DisallowDirective, value)
+}
+
+func (e *explainer) HandleSitemap(int, string) {}
+
+func (e *explainer) HandleUnknownAction(int, string, string) {}
+
+func (e *explainer) handleRule(lineNum int, directive, pattern string) {
+	if !e.seenGlobalAgent && !e.seenSpecificAgent {
+		return
+	}
+	e.seenSeparator = true
+	if !matches(e.path, pattern) {
+		return
+	}
+	rule := &MatchedRule{Directive: directive, Pattern: pattern, Line: lineNum}
+	priority := len(pattern)
+	if e.seenSpecificAgent {
+		e.specific.rule(directive).update(priority, rule)
+	}
+	if e.seenGlobalAgent {
+		e.global.rule(directive).update(priority, rule)
+	}
+}
+
+func (g *groupMatch) rule(directive string) *ruleMatch {
+	if directive == AllowDirective {
+		return &g.allow
+	}
+	return &g.disallow
+}
+
+func isGlobalAgent(value string) bool {
+	return len(value) >= 1 && value[0] == '*' && (len(value) == 1 || value[1] == ' ' || value[1] == '\t')
+}
+
+// extractUserAgent returns the product token of the robots.txt user-agent value ([a-zA-Z_-] characters).
+func extractUserAgent(value string) string {
+	end := strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '_')
+	})
+	if end < 0 {
+		return value
+	}
+	return value[:end]
+}
+
+// matches reports whether the path matches the robots.txt pattern. '*' matches any sequence of characters
+// and a trailing '$' anchors the pattern to the end of the path.
+func matches(path, pattern string) bool {
+	pathLen := len(path)
+	pos := make([]int, pathLen+1)
+	numPos := 1
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == '$' && i+1 == len(pattern) {
+			return pos[numPos-1] == pathLen
+		}
+		if c == '*' {
+			numPos = pathLen - pos[0] + 1
+			for j := 1; j < numPos; j++ {
+				pos[j] = pos[j-1] + 1
+			}
+			continue
+		}
+		newNumPos := 0
+		for j := 0; j < numPos; j++ {
+			if pos[j] < pathLen && path[pos[j]] == c {
+				pos[newNumPos] = pos[j] + 1
+				newNumPos++
+			}
+		}
+		numPos = newNumPos
+		if numPos == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// getPathParamsQuery extracts the path, params and query of the url the same way grobotstxt does.
+// The fragment is dropped and the result always starts with '/'.
+func getPathParamsQuery(url string) string {
+	searchStart := 0
+	if strings.HasPrefix(url, "//") {
+		searchStart = 2
+	}
+	earlyPath := indexAnyFrom(url, "/?;", searchStart)
+	protocolEnd := indexFrom(url, "://", searchStart)
+	if earlyPath >= 0 && (protocolEnd < 0 || earlyPath < protocolEnd) {
+		protocolEnd = -1
+	}
+	if protocolEnd < 0 {
+		protocolEnd = searchStart
+	} else {
+		protocolEnd += 3
+	}
+	pathStart := indexAnyFrom(url, "/?;", protocolEnd)
+	if pathStart < 0 {
+		return "/"
+	}
+	hashPos := indexFrom(url, "#", searchStart)
+	if hashPos >= 0 && hashPos < pathStart {
+		return "/"
+	}
+	pathEnd := len(url)
+	if hashPos >= 0 {
+		pathEnd = hashPos
+	}
+	if url[pathStart] != '/' {
+		return "/" + url[pathStart:pathEnd]
+	}
+	return url[pathStart:pathEnd]
+}
+
+func indexFrom(s, substr string, from int) int {
+	if from > len(s) {
+		return -1
+	}
+	if i := strings.Index(s[from:], substr); i >= 0 {
+		return i + from
+	}
+	return -1
+}
+
+func indexAnyFrom(s, chars string, from int) int {
+	if from > len(s) {
+		return -1
+	}
+	if i := strings.IndexAny(s[from:], chars); i >= 0 {
+		return i + from
+	}
+	return -1
+}
+
+// IsHtml reports whether the robots.txt response is an HTML page, e.g. an error page served with 200 status.
+// The content type is checked first, then the beginning of the body.
+func IsHtml(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil &&
+		(mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+		return true
+	}
+	start := bytes.ToLower(bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n"))
+	for _, prefix := range []string{"<!doctype html", "<html", "<head", "<body"} {
+		if bytes.HasPrefix(start, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
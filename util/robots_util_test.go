@@ -0,0 +1,64 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Explain(t *testing.T) {
+	testSet := []struct {
+		name                string
+		robotsTxt           string
+		userAgent           string
+		url                 string
+		expectedExplanation *Explanation
+	}{
+		{
+			name:      "allow wins the tiebreak with equal specificity",
+			robotsTxt: "User-agent: *\nDisallow: /page\nAllow: /page\n",
+			userAgent: "bot",
+			url:       "https://example.com/page",
+			expectedExplanation: &Explanation{
+				Allowed: true,
+				Rule:    &MatchedRule{Directive: AllowDirective, Pattern: "/page", Line: 3},
+				Note:    "allow and disallow rules have equal specificity. The allow rule wins the tiebreak",
+			},
+		},
+		{
+			name:      "longest rule wins",
+			robotsTxt: "User-agent: *\nAllow: /page\nDisallow: /page/private\n",
+			userAgent: "bot",
+			url:       "https://example.com/page/private/1",
+			expectedExplanation: &Explanation{
+				Allowed: false,
+				Rule:    &MatchedRule{Directive: DisallowDirective, Pattern: "/page/private", Line: 3},
+			},
+		},
+		{
+			name:      "specific group is used instead of the global one",
+			robotsTxt: "User-agent: *\nDisallow: /\n\nUser-agent: bot\nDisallow: /private\n",
+			userAgent: "bot",
+			url:       "https://example.com/public",
+			expectedExplanation: &Explanation{
+				Allowed: true,
+				Note:    "the group for the user agent has no rule matching the url",
+			},
+		},
+		{
+			name:      "no rule matches the url",
+			robotsTxt: "User-agent: *\nDisallow: /private\n",
+			userAgent: "bot",
+			url:       "https://example.com/public",
+			expectedExplanation: &Explanation{
+				Allowed: true,
+				Note:    "no rule matches the url",
+			},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expectedExplanation, Explain(test.robotsTxt, test.userAgent, test.url))
+		})
+	}
+}
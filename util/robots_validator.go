@@ -0,0 +1,289 @@
+package util
+
+import (
+	"fmt"
+	u "net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jimsmart/grobotstxt"
+)
+
+const (
+	// SeverityError is the problem of a line that is ignored by the parser.
+	SeverityError = "error"
+	// SeverityWarning is the problem of a line that is applied, but likely doesn't work as intended.
+	SeverityWarning = "warning"
+)
+
+// RobotsTxtReport describes the structure of the robots.txt file and the problems found while parsing it.
+type RobotsTxtReport struct {
+	UserAgentGroups int                `json:"user_agent_groups"`
+	Sitemaps        []string           `json:"sitemaps"`
+	Warnings        []RobotsTxtWarning `json:"warnings"`
+}
+
+// RobotsTxtWarning is a problem of the robots.txt file. Line and Column are 1-based and point to the offending text,
+// so the author can jump to it in an editor. They are empty for the problems of the whole file.
+type RobotsTxtWarning struct {
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+// String returns the warning as 'line N: message', e.g. for the logs.
+func (w RobotsTxtWarning) String() string {
+	if w.Line == 0 {
+		return w.Message
+	}
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// ValidateRobotsTxt parses the robots.txt file the same way as the matcher and reports the lines that are ignored
+// or likely don't work as intended. The parser is lenient: the whitespace around keys and values is trimmed and
+// a directive without the colon (`Disallow /admin`) or without the space after it (`Disallow:/admin`) is accepted,
+// so such lines are not reported. Consecutive user-agent lines share one group of rules. The same user agent
+// in separate groups is reported, because parsers differ in whether such groups are merged.
+func ValidateRobotsTxt(robotsTxt string) *RobotsTxtReport {
+	v := &validator{
+		report:       &RobotsTxtReport{Sitemaps: []string{}, Warnings: []RobotsTxtWarning{}},
+		lines:        robotsTxtLines(robotsTxt),
+		handledLines: make(map[int]bool),
+		agentLines:   make(map[string][]int),
+		agentGroup:   make(map[string]int),
+	}
+	grobotstxt.Parse(robotsTxt, v)
+	v.checkIgnoredLines()
+
+	v.report.UserAgentGroups = v.groups
+	warnings := v.report.Warnings
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Line < warnings[j].Line })
+	return v.report
+}
+
+// robotsTxtLines splits the file into lines the same way as the parser: '\r\n', '\n' and '\r' end a line.
+func robotsTxtLines(robotsTxt string) []string {
+	robotsTxt = strings.ReplaceAll(robotsTxt, "\r\n", "\n")
+	robotsTxt = strings.ReplaceAll(robotsTxt, "\r", "\n")
+	return strings.Split(robotsTxt, "\n")
+}
+
+// CountUserAgentGroups returns the number of user-agent groups in the robots.txt file.
+// Consecutive user-agent lines share one group of rules, so they are counted as one group.
+func CountUserAgentGroups(robotsTxt string) int {
+	c := &groupCounter{}
+	grobotstxt.Parse(robotsTxt, c)
+	return c.groups
+}
+
+// groupCounter implements grobotstxt.ParseHandler. A new group starts with the user-agent line after a rule.
+type groupCounter struct {
+	groups    int
+	lastAgent bool
+}
+
+func (g *groupCounter) HandleRobotsStart() {}
+
+func (g *groupCounter) HandleRobotsEnd() {}
+
+func (g *groupCounter) HandleUserAgent(_ int, _ string) {
+	if !g.lastAgent {
+		g.groups++
+	}
+	g.lastAgent = true
+}
+
+func (g *groupCounter) HandleAllow(_ int, _ string) {
+	g.handleRule()
+}
+
+func (g *groupCounter) HandleDisallow(_ int, _ string) {
+	g.handleRule()
+}
+
+func (g *groupCounter) handleRule() {
+	g.lastAgent = false
+}
+
+func (g *groupCounter) HandleSitemap(_ int, _ string) {}
+
+func (g *groupCounter) HandleUnknownAction(_ int, _, _ string) {}
+
+// IsUnparseable reports whether the robots.txt has content, but no line of it is a 'key: value' directive, e.g. a
+// binary file or a file of another format. Such robots.txt allows everything, but the decision is meaningless.
+// A robots.txt with only comments and blank lines is not unparseable.
+func IsUnparseable(robotsTxt string) bool {
+	if HasNoRules(robotsTxt) {
+		return false
+	}
+	d := &directiveCounter{}
+	grobotstxt.Parse(robotsTxt, d)
+	return d.directives == 0
+}
+
+// directiveCounter implements grobotstxt.ParseHandler and counts the lines recognized as directives.
+type directiveCounter struct {
+	directives int
+}
+
+func (d *directiveCounter) HandleRobotsStart() {}
+
+func (d *directiveCounter) HandleRobotsEnd() {}
+
+func (d *directiveCounter) HandleUserAgent(_ int, _ string) { d.directives++ }
+
+func (d *directiveCounter) HandleAllow(_ int, _ string) { d.directives++ }
+
+func (d *directiveCounter) HandleDisallow(_ int, _ string) { d.directives++ }
+
+func (d *directiveCounter) HandleSitemap(_ int, _ string) { d.directives++ }
+
+func (d *directiveCounter) HandleUnknownAction(_ int, _, _ string) { d.directives++ }
+
+// validator implements grobotstxt.ParseHandler.
+type validator struct {
+	groupCounter
+	report       *RobotsTxtReport
+	lines        []string
+	handledLines map[int]bool
+	seenAgent    bool
+	// agentLines is the first line of every group of the user agent
+	agentLines map[string][]int
+	// agentGroup is the group the user agent was last seen in
+	agentGroup map[string]int
+}
+
+func (v *validator) HandleUserAgent(lineNum int, value string) {
+	v.handledLines[lineNum] = true
+	if value == "" {
+		v.warn(lineNum, false, SeverityWarning, "user-agent is empty")
+	}
+	v.groupCounter.HandleUserAgent(lineNum, value)
+	v.seenAgent = true
+	if value != "" {
+		v.trackAgent(lineNum, value)
+	}
+}
+
+func (v *validator) HandleRobotsEnd() {
+	for agent, lines := range v.agentLines {
+		if len(lines) < 2 {
+			continue
+		}
+		numbers := make([]string, 0, len(lines))
+		for _, line := range lines {
+			numbers = append(numbers, fmt.Sprint(line))
+		}
+		v.warn(lines[1], true, SeverityWarning, fmt.Sprintf("user-agent '%s' is repeated in separate groups at "+
+			"lines %s. Parsers differ in whether the groups are merged", agent, strings.Join(numbers, ", ")))
+	}
+}
+
+// trackAgent records the line of the user agent if it is the first line of the user agent in the current group.
+func (v *validator) trackAgent(lineNum int, value string) {
+	agent := "*"
+	if !isGlobalAgent(value) {
+		agent = strings.ToLower(extractUserAgent(value))
+	}
+	if group, ok := v.agentGroup[agent]; ok && group == v.groups {
+		return
+	}
+	v.agentGroup[agent] = v.groups
+	v.agentLines[agent] = append(v.agentLines[agent], lineNum)
+}
+
+func (v *validator) HandleAllow(lineNum int, value string) {
+	v.checkRule(lineNum, AllowDirective, value)
+}
+
+func (v *validator) HandleDisallow(lineNum int, value string) {
+	v.checkRule(lineNum, DisallowDirective, value)
+}
+
+func (v *validator) HandleSitemap(lineNum int, value string) {
+	v.handledLines[lineNum] = true
+	v.report.Sitemaps = append(v.report.Sitemaps, value)
+	if parsedUrl, err := u.Parse(value); err != nil || parsedUrl.Scheme == "" || parsedUrl.Host == "" {
+		v.warn(lineNum, true, SeverityWarning, "sitemap url should be absolute")
+	}
+}
+
+func (v *validator) HandleUnknownAction(lineNum int, action, _ string) {
+	v.handledLines[lineNum] = true
+	v.warn(lineNum, false, SeverityError, fmt.Sprintf("unsupported directive '%s' is ignored", action))
+}
+
+func (v *validator) checkRule(lineNum int, directive, value string) {
+	v.handledLines[lineNum] = true
+	v.groupCounter.handleRule()
+	if !v.seenAgent {
+		v.warn(lineNum, false, SeverityError, fmt.Sprintf("%s rule before any user-agent is ignored", directive))
+	}
+	if value != "" && !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "*") {
+		v.warn(lineNum, true, SeverityWarning, fmt.Sprintf("%s path should start with '/' or '*'", directive))
+	}
+	// the whitespace inside the value is a part of the pattern, e.g. 'Disallow: /admin /private' is one path
+	if strings.ContainsAny(value, " \t") {
+		v.warn(lineNum, true, SeverityWarning,
+			fmt.Sprintf("%s path contains whitespace. The content after it is a part of the path", directive))
+	}
+}
+
+// checkIgnoredLines reports the lines that are not comments, but are skipped by the parser.
+func (v *validator) checkIgnoredLines() {
+	for i, line := range v.lines {
+		lineNum := i + 1
+		line, _, _ = strings.Cut(line, "#")
+		if strings.TrimSpace(line) != "" && !v.handledLines[lineNum] {
+			v.warn(lineNum, false, SeverityError, "line is not a 'key: value' directive and is ignored")
+		}
+	}
+}
+
+// warn reports the problem of the line. The column points to the value after the 'key:' separator if atValue is set
+// and the line has a value, otherwise to the first non-whitespace character. The column is taken from the raw line,
+// because the parser percent-encodes the non-ASCII characters of the allow and disallow values.
+func (v *validator) warn(lineNum int, atValue bool, severity, message string) {
+	warning := RobotsTxtWarning{Line: lineNum, Message: message, Severity: severity}
+	if lineNum >= 1 && lineNum <= len(v.lines) {
+		line := v.lines[lineNum-1]
+		warning.Text = strings.TrimSpace(line)
+		start := -1
+		if atValue {
+			start = valueStart(line)
+		}
+		if start < 0 {
+			start = max(strings.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) }), 0)
+		}
+		// the column is counted in characters, as in editors
+		warning.Column = utf8.RuneCountInString(line[:start]) + 1
+	}
+	v.report.Warnings = append(v.report.Warnings, warning)
+}
+
+// valueStart returns the byte index of the value after the 'key:' separator of the line, or -1 if the line has
+// no value. The key and the value may also be separated by whitespace only, the same as in grobotstxt.
+func valueStart(line string) int {
+	content, _, _ := strings.Cut(line, "#")
+	key := strings.IndexFunc(content, func(r rune) bool { return !unicode.IsSpace(r) })
+	if key < 0 {
+		return -1
+	}
+	sep := strings.IndexByte(content, ':')
+	if sep < 0 {
+		sep = strings.IndexAny(content[key:], " \t")
+		if sep < 0 {
+			return -1
+		}
+		sep += key
+	}
+	value := strings.IndexFunc(content[sep+1:], func(r rune) bool { return !unicode.IsSpace(r) })
+	if value < 0 {
+		return -1
+	}
+	return sep + 1 + value
+}
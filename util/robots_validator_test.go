@@ -0,0 +1,236 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateRobotsTxt(t *testing.T) {
+	testSet := []struct {
+		name             string
+		robotsTxt        string
+		expectedGroups   int
+		expectedSitemaps []string
+		expectedWarnings []RobotsTxtWarning
+	}{
+		{
+			name: "well-formed file",
+			robotsTxt: "# comment\n" +
+				"User-agent: googlebot\n" +
+				"Disallow: /private\n" +
+				"\n" +
+				"User-agent: *\n" +
+				"Allow: /\n" +
+				"Sitemap: https://example.com/sitemap.xml\n",
+			expectedGroups:   2,
+			expectedSitemaps: []string{"https://example.com/sitemap.xml"},
+			expectedWarnings: []RobotsTxtWarning{},
+		},
+		{
+			name:             "empty file",
+			robotsTxt:        "",
+			expectedGroups:   0,
+			expectedSitemaps: []string{},
+			expectedWarnings: []RobotsTxtWarning{},
+		},
+		{
+			name: "malformed file",
+			robotsTxt: "Disallow: /before-agent\n" +
+				"User-agent: *\n" +
+				"Disallow: private\n" +
+				"Crawl-delay: 10\n" +
+				"this line is broken\n" +
+				"Sitemap: /sitemap.xml\n",
+			expectedGroups:   1,
+			expectedSitemaps: []string{"/sitemap.xml"},
+			expectedWarnings: []RobotsTxtWarning{
+				{Line: 1, Column: 1, Text: "Disallow: /before-agent",
+					Message: "disallow rule before any user-agent is ignored", Severity: SeverityError},
+				{Line: 3, Column: 11, Text: "Disallow: private",
+					Message: "disallow path should start with '/' or '*'", Severity: SeverityWarning},
+				{Line: 4, Column: 1, Text: "Crawl-delay: 10",
+					Message: "unsupported directive 'Crawl-delay' is ignored", Severity: SeverityError},
+				{Line: 5, Column: 1, Text: "this line is broken",
+					Message: "line is not a 'key: value' directive and is ignored", Severity: SeverityError},
+				{Line: 6, Column: 10, Text: "Sitemap: /sitemap.xml",
+					Message: "sitemap url should be absolute", Severity: SeverityWarning},
+			},
+		},
+		{
+			name: "duplicated user-agent groups",
+			robotsTxt: "User-agent: *\n" +
+				"Disallow: /a\n" +
+				"\n" +
+				"User-agent: googlebot\n" +
+				"User-agent: *\n" +
+				"Disallow: /b\n" +
+				"\n" +
+				"User-agent: Googlebot/2.1\n" +
+				"Disallow: /c\n" +
+				"\n" +
+				"User-agent: *\n" +
+				"Allow: /d\n",
+			expectedGroups:   4,
+			expectedSitemaps: []string{},
+			expectedWarnings: []RobotsTxtWarning{
+				{Line: 5, Column: 13, Text: "User-agent: *",
+					Message: "user-agent '*' is repeated in separate groups at lines 1, 5, 11. " +
+						"Parsers differ in whether the groups are merged", Severity: SeverityWarning},
+				{Line: 8, Column: 13, Text: "User-agent: Googlebot/2.1",
+					Message: "user-agent 'googlebot' is repeated in separate groups at lines 4, 8. " +
+						"Parsers differ in whether the groups are merged", Severity: SeverityWarning},
+			},
+		},
+		{
+			name: "lenient directives",
+			robotsTxt: "User-agent:googlebot\n" +
+				"Disallow:/admin\n" +
+				"Allow: /admin/public  \t\n" +
+				"  Disallow :  /tmp\n" +
+				"Disallow /private\n" +
+				"\n" +
+				"User-agent: *\t\n" +
+				"Disallow:\n",
+			expectedGroups:   2,
+			expectedSitemaps: []string{},
+			expectedWarnings: []RobotsTxtWarning{},
+		},
+		{
+			name: "trailing content after the path",
+			robotsTxt: "User-agent: *\n" +
+				"Disallow: /admin /private\n" +
+				"Disallow /a /b\n",
+			expectedGroups:   1,
+			expectedSitemaps: []string{},
+			expectedWarnings: []RobotsTxtWarning{
+				{Line: 2, Column: 11, Text: "Disallow: /admin /private",
+					Message:  "disallow path contains whitespace. The content after it is a part of the path",
+					Severity: SeverityWarning},
+				{Line: 3, Column: 1, Text: "Disallow /a /b",
+					Message: "line is not a 'key: value' directive and is ignored", Severity: SeverityError},
+			},
+		},
+		{
+			name:             "same user agent in one group",
+			robotsTxt:        "User-agent: *\nUser-agent: *\nDisallow: /a\n",
+			expectedGroups:   1,
+			expectedSitemaps: []string{},
+			expectedWarnings: []RobotsTxtWarning{},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			report := ValidateRobotsTxt(test.robotsTxt)
+
+			assert.Equal(tt, test.expectedGroups, report.UserAgentGroups)
+			assert.Equal(tt, test.expectedSitemaps, report.Sitemaps)
+			assert.Equal(tt, test.expectedWarnings, report.Warnings)
+		})
+	}
+}
+
+func Test_ValidateRobotsTxt_Columns(t *testing.T) {
+	testSet := []struct {
+		name             string
+		robotsTxt        string
+		expectedWarnings []RobotsTxtWarning
+	}{
+		{
+			name:      "indented line",
+			robotsTxt: "User-agent: *\n\t  Disallow: admin\n",
+			expectedWarnings: []RobotsTxtWarning{{Line: 2, Column: 14, Text: "Disallow: admin",
+				Message: "disallow path should start with '/' or '*'", Severity: SeverityWarning}},
+		},
+		{
+			name:      "column counts characters",
+			robotsTxt: "# r\u00e8gles\r\nUser-agent: *\r\nDisallow: /\u00e9t\u00e9 /x\r\n",
+			expectedWarnings: []RobotsTxtWarning{{Line: 3, Column: 11, Text: "Disallow: /\u00e9t\u00e9 /x",
+				Message:  "disallow path contains whitespace. The content after it is a part of the path",
+				Severity: SeverityWarning}},
+		},
+		{
+			name:      "value repeated in the key",
+			robotsTxt: "User-agent: *\nDisallow: disallow\n",
+			expectedWarnings: []RobotsTxtWarning{{Line: 2, Column: 11, Text: "Disallow: disallow",
+				Message: "disallow path should start with '/' or '*'", Severity: SeverityWarning}},
+		},
+		{
+			name:      "whitespace separator",
+			robotsTxt: "User-agent: *\nDisallow  admin # no colon\n",
+			expectedWarnings: []RobotsTxtWarning{{Line: 2, Column: 11, Text: "Disallow  admin # no colon",
+				Message: "disallow path should start with '/' or '*'", Severity: SeverityWarning}},
+		},
+		{
+			name:      "empty user-agent",
+			robotsTxt: "User-agent:\nDisallow: /\n",
+			expectedWarnings: []RobotsTxtWarning{{Line: 1, Column: 1, Text: "User-agent:",
+				Message: "user-agent is empty", Severity: SeverityWarning}},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expectedWarnings, ValidateRobotsTxt(test.robotsTxt).Warnings)
+		})
+	}
+}
+
+func Test_CountUserAgentGroups(t *testing.T) {
+	testSet := []struct {
+		name      string
+		robotsTxt string
+		expected  int
+	}{
+		{
+			name:      "no groups",
+			robotsTxt: "Sitemap: https://example.com/sitemap.xml",
+			expected:  0,
+		},
+		{
+			name:      "consecutive user-agent lines are merged into one group",
+			robotsTxt: "User-agent: googlebot\nUser-agent: bingbot\n# comment\nUser-agent: *\nDisallow: /private",
+			expected:  1,
+		},
+		{
+			name: "separate groups",
+			robotsTxt: "User-agent: googlebot\nDisallow: /private\n\nUser-agent: bingbot\nAllow: /\n" +
+				"User-agent: *\nDisallow: /",
+			expected: 3,
+		},
+		{
+			name:      "merged and separate groups",
+			robotsTxt: "User-agent: a\nUser-agent: b\nDisallow: /a\nUser-agent: c\nAllow: /c\nDisallow: /d",
+			expected:  2,
+		},
+		{
+			name:      "user-agent without rules",
+			robotsTxt: "User-agent: googlebot\nDisallow: /\nUser-agent: *",
+			expected:  2,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, CountUserAgentGroups(test.robotsTxt))
+		})
+	}
+}
+
+func Test_IsUnparseable(t *testing.T) {
+	testSet := []struct {
+		name      string
+		robotsTxt string
+		expected  bool
+	}{
+		{name: "rules", robotsTxt: "User-agent: *\nDisallow: /", expected: false},
+		{name: "unknown directive", robotsTxt: "Crawl-delay: 10", expected: false},
+		{name: "empty", robotsTxt: "", expected: false},
+		{name: "comments only", robotsTxt: "# nothing here\n\n", expected: false},
+		{name: "html page", robotsTxt: "<html>\n<body>not a robots file at all</body>\n</html>", expected: true},
+		{name: "text without directives", robotsTxt: "this is not robots.txt\n# comment", expected: true},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, IsUnparseable(test.robotsTxt))
+		})
+	}
+}